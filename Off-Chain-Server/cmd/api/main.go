@@ -6,10 +6,14 @@ import (
     "log"
     "net/http"
     "os"
+    "time"
 
+    "github.com/ethereum/go-ethereum/accounts/abi/bind"
+    "github.com/ethereum/go-ethereum/ethclient"
     "github.com/paxeer/offchain-server/internal/api"
     "github.com/paxeer/offchain-server/internal/config"
     "github.com/paxeer/offchain-server/internal/db"
+    "github.com/paxeer/offchain-server/internal/indexer"
 )
 
 func main() {
@@ -18,6 +22,7 @@ func main() {
 
     c, err := config.Load(*cfgPath)
     if err != nil { log.Fatalf("load config: %v", err) }
+    if len(c.Auth.Domains) == 0 { log.Printf("[warn] auth.domains is empty; SIWE logins will be rejected") }
 
     database, err := db.Connect(context.Background(), c.Postgres.DSN)
     if err != nil { log.Fatalf("db connect: %v", err) }
@@ -27,10 +32,29 @@ func main() {
         log.Fatalf("db migrate: %v", err)
     }
 
+    abis, err := indexer.LoadABIs()
+    if err != nil { log.Fatalf("load abis: %v", err) }
+
+    // RPC is optional for the API: chain reads are skipped when it is unavailable.
+    var rpc bind.ContractCaller
+    if c.RPC.HTTP != "" {
+        if cli, err := ethclient.Dial(c.RPC.HTTP); err == nil {
+            defer cli.Close()
+            rpc = cli
+        } else {
+            log.Printf("[warn] rpc dial failed (%v), chain reads disabled", err)
+        }
+    }
+
     addr := os.Getenv("PAXEER_API_ADDR")
     if addr == "" { addr = ":8080" }
 
-    srv := &http.Server{ Addr: addr, Handler: api.New(database.Pool) }
+    handler := api.New(database.Pool, c, abis, rpc)
+    handler.CheckUSDCDecimals(context.Background())
+    go handler.ListenPoolActivity(context.Background())
+    go handler.CleanupAuthEvery(context.Background(), time.Duration(c.Auth.JanitorIntervalSecs)*time.Second)
+
+    srv := &http.Server{ Addr: addr, Handler: handler.WithRateLimits(context.Background()) }
     log.Printf("API listening on %s", addr)
     log.Fatal(srv.ListenAndServe())
 }
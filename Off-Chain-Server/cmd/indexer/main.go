@@ -43,11 +43,28 @@ func main() {
 		log.Fatalf("new indexer: %v", err)
 	}
 	defer ix.Close()
+	ix.MilestonesFor = c.MilestonesFor
+	ix.USDCDecimals = c.Contracts.USDCDecimals
 
 	// context with cancel on SIGINT/SIGTERM
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	if err := ix.LoadMissingPoolInfo(ctx); err != nil {
+		log.Printf("[warn] load missing pool info: %v", err)
+	}
+	if _, err := ix.Repo.PromoteDrafts(ctx, ""); err != nil {
+		log.Printf("[warn] promote metadata drafts: %v", err)
+	}
+
+	go func() {
+		if err := ix.BackfillTraders(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("[warn] backfill traders: %v", err)
+		}
+	}()
+
+	go ix.RefreshLeaderboardsEvery(ctx, time.Duration(c.Indexer.LeaderboardRefreshSecs)*time.Second)
+
 	// Start backfill and live subscription/polling
 	go func() {
 		if err := ix.Backfill(ctx, c.Indexer.StartBlock); err != nil {
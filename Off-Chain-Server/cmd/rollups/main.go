@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/paxeer/offchain-server/internal/config"
+	"github.com/paxeer/offchain-server/internal/db"
+	"github.com/paxeer/offchain-server/internal/indexer"
+)
+
+// rollups rebuilds the derived tables the indexer maintains incrementally.
+func main() {
+	cfgPath := flag.String("config", "configs/config.yaml", "path to config.yaml")
+	pool := flag.String("pool", "", "only rebuild this pool address (default: all pools)")
+	trader := flag.String("trader", "", "only rebuild positions of this trader address (default: all traders)")
+	flag.Parse()
+
+	c, err := config.Load(*cfgPath)
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+
+	ctx := context.Background()
+	database, err := db.Connect(ctx, c.Postgres.DSN)
+	if err != nil {
+		log.Fatalf("db connect: %v", err)
+	}
+	defer database.Close()
+
+	if err := database.Migrate(ctx, "migrations"); err != nil {
+		log.Fatalf("db migrate: %v", err)
+	}
+
+	if *trader != "" {
+		if !common.IsHexAddress(*trader) {
+			log.Fatalf("invalid -trader %q", *trader)
+		}
+		*trader = common.HexToAddress(*trader).Hex()
+	}
+
+	repo := indexer.NewRepo(database.Pool)
+	log.Printf("rebuilding candles (pool=%q)", *pool)
+	if err := repo.RebuildCandles(ctx, *pool); err != nil {
+		log.Fatalf("rebuild candles: %v", err)
+	}
+	log.Printf("rebuilding positions (trader=%q)", *trader)
+	if err := repo.RebuildPositions(ctx, *trader); err != nil {
+		log.Fatalf("rebuild positions: %v", err)
+	}
+	log.Println("refreshing leaderboards")
+	if err := repo.RefreshLeaderboards(ctx); err != nil {
+		log.Fatalf("refresh leaderboards: %v", err)
+	}
+	log.Println("Rollups rebuilt.")
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// accountAddress parses the {address} segment of /accounts/{address}/{suffix}
+// into the checksummed form the indexer stores traders in.
+func accountAddress(path, suffix string) (string, bool) {
+	a := extractBetween(path, "/accounts/", suffix)
+	if !common.IsHexAddress(a) {
+		return "", false
+	}
+	return common.HexToAddress(a).Hex(), true
+}
+
+// balanceOf reads ERC20 balanceOf(owner). ok is false when no RPC is configured.
+func (s *Server) balanceOf(ctx context.Context, token, owner common.Address) (*big.Int, bool, error) {
+	if s.RPC == nil {
+		return nil, false, nil
+	}
+	data, err := s.ABIs.ERC20.Pack("balanceOf", owner)
+	if err != nil {
+		return nil, false, err
+	}
+	out, err := s.RPC.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	vals, err := s.ABIs.ERC20.Unpack("balanceOf", out)
+	if err != nil {
+		return nil, false, err
+	}
+	v, _ := vals[0].(*big.Int)
+	return v, v != nil, nil
+}
+
+type tokenHolding struct {
+	Pool      string  `json:"pool"`
+	Token     string  `json:"token"`
+	Name      *string `json:"name,omitempty"`
+	Symbol    *string `json:"symbol,omitempty"`
+	LogoURL   *string `json:"logoUrl,omitempty"`
+	Balance   string  `json:"balance"`
+	Formatted string  `json:"balanceFormatted"`
+	// Source is "onchain" (balanceOf) or "swaps" (net launchpad flows).
+	Source             string  `json:"source"`
+	NetFromSwaps       string  `json:"netFromSwaps"`
+	SpotX18            *string `json:"spotX18"`
+	ValueUSDC          string  `json:"valueUSDC"`
+	ValueUSDCFormatted string  `json:"valueUSDCFormatted"`
+}
+
+type lpHolding struct {
+	Pool               string `json:"pool"`
+	Token              string `json:"token"`
+	LPTokens           string `json:"lpTokens"`
+	TotalLPTokens      string `json:"totalLPTokens"`
+	ShareUSDC          string `json:"shareUSDC"`
+	ShareToken         string `json:"shareToken"`
+	ValueUSDC          string `json:"valueUSDC"`
+	ValueUSDCFormatted string `json:"valueUSDCFormatted"`
+}
+
+func bigOrZero(s *string) *big.Int {
+	if s != nil {
+		if v, ok := new(big.Int).SetString(*s, 10); ok {
+			return v
+		}
+	}
+	return new(big.Int)
+}
+
+// valueAtSpot converts a token amount to USDC base units at an X18 spot price.
+func valueAtSpot(amount, spot *big.Int) *big.Int {
+	v := new(big.Int).Mul(amount, spot)
+	return v.Quo(v, one18)
+}
+
+// GET /accounts/{address}/portfolio
+// Launchpad token and LP positions of an address valued at current spot.
+// Token balances come from balanceOf when RPC is available and otherwise from
+// the address's net swap and liquidity flows; LP positions are derived from
+// liquidity events and valued at their share of the real reserves.
+func (s *Server) handlePortfolio(w http.ResponseWriter, r *http.Request) {
+	addr, ok := accountAddress(r.URL.Path, "/portfolio")
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid address"})
+		return
+	}
+	usdcDec := s.usdcDecimals()
+	total := new(big.Int)
+
+	rows, err := s.DB.Query(r.Context(), `
+		WITH flows AS (
+			SELECT pool_address, CASE WHEN usdc_to_token THEN amount_out ELSE -amount_in END AS token_delta
+			FROM swaps WHERE trader = $1
+			UNION ALL
+			SELECT pool_address, CASE WHEN event_type = 'add' THEN -amount_token ELSE amount_token END
+			FROM liquidity_events WHERE trader = $1
+		)
+		SELECT f.pool_address, p.token_address, p.spot_x18::text, p.token_decimals, m.name, m.symbol, m.logo_url, SUM(f.token_delta)::text
+		FROM flows f
+		JOIN pools p ON p.pool_address = f.pool_address
+		LEFT JOIN pool_metadata m ON m.pool_address = f.pool_address
+		GROUP BY f.pool_address, p.token_address, p.spot_x18, p.token_decimals, m.name, m.symbol, m.logo_url
+		ORDER BY f.pool_address`, addr)
+	if err != nil { writeErr(w, err); return }
+	type flowRow struct {
+		h   tokenHolding
+		dec *int16
+	}
+	var flows []flowRow
+	for rows.Next() {
+		var fr flowRow
+		if err := rows.Scan(&fr.h.Pool, &fr.h.Token, &fr.h.SpotX18, &fr.dec, &fr.h.Name, &fr.h.Symbol, &fr.h.LogoURL, &fr.h.NetFromSwaps); err != nil {
+			rows.Close()
+			writeErr(w, err)
+			return
+		}
+		flows = append(flows, fr)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil { writeErr(w, err); return }
+
+	tokens := []tokenHolding{}
+	for _, fr := range flows {
+		h := fr.h
+		bal := bigOrZero(&h.NetFromSwaps)
+		if bal.Sign() < 0 { bal.SetInt64(0) }
+		h.Source = "swaps"
+		onchain, ok, err := s.balanceOf(r.Context(), common.HexToAddress(h.Token), common.HexToAddress(addr))
+		if err != nil {
+			// Keep the swap-derived balance rather than failing the whole portfolio.
+			log.Printf("[warn] balanceOf %s for %s: %v", h.Token, addr, err)
+		} else if ok {
+			bal, h.Source = onchain, "onchain"
+		}
+		if bal.Sign() == 0 { continue }
+		tokenDec := defaultTokenDecimals
+		if fr.dec != nil { tokenDec = int(*fr.dec) }
+		value := valueAtSpot(bal, bigOrZero(h.SpotX18))
+		total.Add(total, value)
+		h.Balance, h.Formatted = bal.String(), formatUnits(bal.String(), tokenDec)
+		h.ValueUSDC, h.ValueUSDCFormatted = value.String(), formatUnits(value.String(), usdcDec)
+		tokens = append(tokens, h)
+	}
+
+	rows, err = s.DB.Query(r.Context(), `
+		SELECT l.pool_address, p.token_address, p.reserve_usdc::text, p.reserve_token::text, p.spot_x18::text,
+			SUM(CASE WHEN l.event_type = 'add' THEN l.lp_amount ELSE -l.lp_amount END)::text,
+			(SELECT SUM(CASE WHEN t.event_type = 'add' THEN t.lp_amount ELSE -t.lp_amount END) FROM liquidity_events t WHERE t.pool_address = l.pool_address)::text
+		FROM liquidity_events l
+		JOIN pools p ON p.pool_address = l.pool_address
+		WHERE l.trader = $1
+		GROUP BY l.pool_address, p.token_address, p.reserve_usdc, p.reserve_token, p.spot_x18
+		HAVING SUM(CASE WHEN l.event_type = 'add' THEN l.lp_amount ELSE -l.lp_amount END) > 0
+		ORDER BY l.pool_address`, addr)
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	lps := []lpHolding{}
+	for rows.Next() {
+		var (
+			h                               lpHolding
+			resUSDC, resToken, spot, totLP *string
+		)
+		if err := rows.Scan(&h.Pool, &h.Token, &resUSDC, &resToken, &spot, &h.LPTokens, &totLP); err != nil { writeErr(w, err); return }
+		lp, supply := bigOrZero(&h.LPTokens), bigOrZero(totLP)
+		h.TotalLPTokens = supply.String()
+		shareUSDC, shareToken := new(big.Int), new(big.Int)
+		if supply.Sign() > 0 {
+			// removeLiquidity pays lp/totalSupply of each real reserve.
+			shareUSDC.Mul(lp, bigOrZero(resUSDC)).Quo(shareUSDC, supply)
+			shareToken.Mul(lp, bigOrZero(resToken)).Quo(shareToken, supply)
+		}
+		value := valueAtSpot(shareToken, bigOrZero(spot))
+		value.Add(value, shareUSDC)
+		total.Add(total, value)
+		h.ShareUSDC, h.ShareToken = shareUSDC.String(), shareToken.String()
+		h.ValueUSDC, h.ValueUSDCFormatted = value.String(), formatUnits(value.String(), usdcDec)
+		lps = append(lps, h)
+	}
+	if err := rows.Err(); err != nil { writeErr(w, err); return }
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"address":                 addr,
+		"tokens":                  tokens,
+		"liquidity":               lps,
+		"totalValueUSDC":          total.String(),
+		"totalValueUSDCFormatted": formatUnits(total.String(), usdcDec),
+	})
+}
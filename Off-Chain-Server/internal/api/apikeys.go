@@ -0,0 +1,160 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// API key scopes. Sessions (cookie or bearer) carry every scope.
+const (
+	scopeRead          = "read"
+	scopeWriteComments = "write:comments"
+	scopeWriteMetadata = "write:metadata"
+)
+
+var apiKeyScopes = map[string]bool{scopeRead: true, scopeWriteComments: true, scopeWriteMetadata: true}
+
+// apiKeyPrefix marks bearer tokens that are API keys rather than session tokens.
+const apiKeyPrefix = "pxk_"
+
+// scopeFor is the scope an API key needs for an authenticated request:
+// reads need read, comment writes write:comments, any other write
+// (profiles, pool metadata, editors, drafts, reverts) write:metadata.
+func scopeFor(r *http.Request) string {
+	switch {
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		return scopeRead
+	case strings.HasPrefix(r.URL.Path, "/comments/"):
+		return scopeWriteComments
+	default:
+		return scopeWriteMetadata
+	}
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	if len(h) > 7 && strings.EqualFold(h[:7], "bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return ""
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyAddress resolves a live API key and records that it was used, at
+// most once per lastSeenGranularity like session activity.
+func (s *Server) apiKeyAddress(r *http.Request, key string) (string, []string, bool) {
+	var (
+		addr   string
+		scopes []string
+		used   *time.Time
+	)
+	hash := hashAPIKey(key)
+	err := s.DB.QueryRow(r.Context(), `
+		SELECT address, scopes, last_used_at FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())`, hash).Scan(&addr, &scopes, &used)
+	if err != nil {
+		return "", nil, false
+	}
+	if used == nil || time.Since(*used) > lastSeenGranularity {
+		_, _ = s.DB.Exec(r.Context(), `
+			UPDATE api_keys SET last_used_at = NOW()
+			WHERE key_hash = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - $2::interval)`, hash, lastSeenGranularity)
+	}
+	return addr, scopes, true
+}
+
+type apiKey struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+const apiKeyColumns = `id, name, key_prefix, scopes, created_at, last_used_at, expires_at, revoked_at`
+
+func scanAPIKey(row pgx.Row) (apiKey, error) {
+	var k apiKey
+	err := row.Scan(&k.ID, &k.Name, &k.Prefix, &k.Scopes, &k.CreatedAt, &k.LastUsedAt, &k.ExpiresAt, &k.RevokedAt)
+	return k, err
+}
+
+// GET /me/api-keys (session required)
+func (s *Server) handleListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	addr, ok := s.requireSession(w, r)
+	if !ok { return }
+	rows, err := s.DB.Query(r.Context(), `SELECT `+apiKeyColumns+` FROM api_keys WHERE address = $1 ORDER BY created_at DESC`, addr)
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	out := []apiKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil { writeErr(w, err); return }
+		out = append(out, k)
+	}
+	if err := rows.Err(); err != nil { writeErr(w, err); return }
+	writeJSON(w, http.StatusOK, out)
+}
+
+// POST /me/api-keys {"name","scopes","expiresInDays"} (session required)
+// The key is only returned here; the server keeps its hash.
+func (s *Server) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	addr, ok := s.requireSession(w, r)
+	if !ok { return }
+	var in struct {
+		Name          string   `json:"name"`
+		Scopes        []string `json:"scopes"`
+		ExpiresInDays int      `json:"expiresInDays"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil { writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid body"}); return }
+	if len(in.Name) > 64 { writeJSON(w, http.StatusBadRequest, map[string]any{"error": "name too long"}); return }
+	if len(in.Scopes) == 0 { in.Scopes = []string{scopeRead} }
+	seen := map[string]bool{}
+	scopes := []string{}
+	for _, sc := range in.Scopes {
+		if !apiKeyScopes[sc] { writeJSON(w, http.StatusBadRequest, map[string]any{"error": "unknown scope: " + sc}); return }
+		if !seen[sc] { seen[sc] = true; scopes = append(scopes, sc) }
+	}
+	if in.ExpiresInDays < 0 || in.ExpiresInDays > 3650 { writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid expiresInDays"}); return }
+	var expires *time.Time
+	if in.ExpiresInDays > 0 {
+		t := time.Now().Add(time.Duration(in.ExpiresInDays) * 24 * time.Hour)
+		expires = &t
+	}
+
+	secret, err := randomHex(32)
+	if err != nil { writeErr(w, err); return }
+	key := apiKeyPrefix + secret
+	k, err := scanAPIKey(s.DB.QueryRow(r.Context(), `
+		INSERT INTO api_keys(address, name, key_prefix, key_hash, scopes, expires_at) VALUES($1,$2,$3,$4,$5,$6)
+		RETURNING `+apiKeyColumns, addr, in.Name, key[:len(apiKeyPrefix)+8], hashAPIKey(key), scopes, expires))
+	if err != nil { writeErr(w, err); return }
+	writeJSON(w, http.StatusCreated, map[string]any{"key": key, "apiKey": k})
+}
+
+// DELETE /me/api-keys/{id} (session required)
+func (s *Server) handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	addr, ok := s.requireSession(w, r)
+	if !ok { return }
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/me/api-keys/"), 10, 64)
+	if err != nil { writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid id"}); return }
+	tag, err := s.DB.Exec(r.Context(), `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND address = $2 AND revoked_at IS NULL`, id, addr)
+	if err != nil { writeErr(w, err); return }
+	if tag.RowsAffected() == 0 { writeJSON(w, http.StatusNotFound, map[string]any{"error": "api key not found"}); return }
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/paxeer/offchain-server/internal/indexer"
+)
+
+// maxCandles bounds how many buckets a single /candles request may span;
+// defaultCandles is the span without a usable ?limit=.
+const (
+	maxCandles     = 1000
+	defaultCandles = 200
+)
+
+type candle struct {
+	BucketTime  time.Time `json:"bucketTime"`
+	Open        string    `json:"open"`
+	High        string    `json:"high"`
+	Low         string    `json:"low"`
+	Close       string    `json:"close"`
+	VolumeUSDC  string    `json:"volumeUSDC"`
+	VolumeToken string    `json:"volumeToken"`
+	Trades      int64     `json:"trades"`
+}
+
+// GET /pools/{pool}/candles?interval=5m&limit=200&from=&to=
+// OHLC and volume come from the candles_* rollups the indexer maintains from
+// swaps and price updates. Empty buckets are forward filled from the previous
+// close and every open carries the previous close.
+func (s *Server) handleCandles(w http.ResponseWriter, r *http.Request) {
+	pool := extractBetween(r.URL.Path, "/pools/", "/candles")
+	interval := r.URL.Query().Get("interval")
+	if interval == "" { interval = "5m" }
+	bucket, ok := bucketSeconds(interval)
+	if !ok || bucket <= 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid interval"})
+		return
+	}
+	limit := parseIntDefault(r.URL.Query().Get("limit"), defaultCandles)
+	if limit <= 0 { limit = defaultCandles }
+	if limit > maxCandles { limit = maxCandles }
+
+	q := r.URL.Query()
+	to := time.Now().UTC()
+	if v := q.Get("to"); v != "" {
+		t, ok := parseTimeParam(v)
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid to"})
+			return
+		}
+		to = t
+	}
+	end := to.Unix() / bucket * bucket
+	start := end - int64(limit-1)*bucket
+	if v := q.Get("from"); v != "" {
+		f, ok := parseTimeParam(v)
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid from"})
+			return
+		}
+		start = f.Unix() / bucket * bucket
+		if start > end {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "from after to"})
+			return
+		}
+		if (end-start)/bucket+1 > maxCandles {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "range too large"})
+			return
+		}
+	}
+
+	// Read the coarsest rollup that evenly divides the interval and
+	// aggregate it upward (identity when the interval is stored as-is).
+	src := indexer.CandleResolutions[0]
+	for _, res := range indexer.CandleResolutions {
+		if bucket%res.Seconds == 0 { src = res }
+	}
+	rows, err := s.DB.Query(r.Context(), `
+	SELECT (floor(extract(epoch from bucket_time) / $4) * $4)::bigint AS bucket,
+		(ARRAY_AGG(open ORDER BY bucket_time ASC))[1] AS open,
+		MAX(high) AS high,
+		MIN(low) AS low,
+		(ARRAY_AGG(close ORDER BY bucket_time DESC))[1] AS close,
+		SUM(volume_usdc) AS volume_usdc,
+		SUM(volume_token) AS volume_token,
+		SUM(trades) AS trades
+	FROM `+src.Table+`
+	WHERE pool_address = $1 AND bucket_time >= to_timestamp($2) AND bucket_time < to_timestamp($3)
+	GROUP BY 1 ORDER BY 1`, pool, start, end+bucket, bucket)
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	agg := map[int64]candle{}
+	for rows.Next() {
+		var b int64
+		var c candle
+		if err := rows.Scan(&b, &c.Open, &c.High, &c.Low, &c.Close, &c.VolumeUSDC, &c.VolumeToken, &c.Trades); err != nil { writeErr(w, err); return }
+		agg[b] = c
+	}
+	if err := rows.Err(); err != nil { writeErr(w, err); return }
+
+	// Seed the carry-over price with the last spot price before the range.
+	var prevClose string
+	err = s.DB.QueryRow(r.Context(), `SELECT price_x18 FROM price_updates WHERE pool_address = $1 AND block_time < to_timestamp($2) ORDER BY block_number DESC, log_index DESC LIMIT 1`, pool, start).Scan(&prevClose)
+	if err != nil { prevClose = "" }
+
+	out := []candle{}
+	for b := start; b <= end; b += bucket {
+		c, traded := agg[b]
+		if !traded {
+			if prevClose == "" { continue }
+			c = candle{Open: prevClose, High: prevClose, Low: prevClose, Close: prevClose, VolumeUSDC: "0", VolumeToken: "0"}
+		} else if prevClose != "" {
+			c.Open = prevClose
+			if cmpNumeric(prevClose, c.High) > 0 { c.High = prevClose }
+			if cmpNumeric(prevClose, c.Low) < 0 { c.Low = prevClose }
+		}
+		c.BucketTime = time.Unix(b, 0).UTC()
+		prevClose = c.Close
+		out = append(out, c)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// cmpNumeric compares two integer NUMERIC strings.
+func cmpNumeric(a, b string) int {
+	x, ok1 := new(big.Int).SetString(a, 10)
+	y, ok2 := new(big.Int).SetString(b, 10)
+	if !ok1 || !ok2 { return 0 }
+	return x.Cmp(y)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/paxeer/offchain-server/internal/config"
+)
+
+func corsServer(origins ...string) *Server {
+	c := &config.Config{}
+	c.API.AllowedOrigins = origins
+	return &Server{Config: c}
+}
+
+func TestOriginAllowed(t *testing.T) {
+	s := corsServer("https://app.paxeer.app/", "https://*.x.app", "http://localhost:3000")
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.paxeer.app", true},
+		{"HTTPS://APP.PAXEER.APP", true},
+		{"https://a.x.app", true},
+		{"https://a.b.x.app", true},
+		{"https://x.app", false},
+		{"https://evilx.app", false},
+		{"https://a.x.app.evil.com", false},
+		{"http://a.x.app", false},
+		{"https://a.x.app:8443", false},
+		{"http://localhost:3000", true},
+		{"http://localhost:3001", false},
+		{"https://localhost:3000", false},
+		{"null", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := s.originAllowed(tt.origin); got != tt.want {
+			t.Errorf("originAllowed(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+	if (&Server{}).originAllowed("https://a.x.app") {
+		t.Error("originAllowed without config = true, want false")
+	}
+}
+
+func TestCheckCSRF(t *testing.T) {
+	s := corsServer("https://*.x.app")
+	tests := []struct {
+		name    string
+		method  string
+		cookie  bool
+		bearer  bool
+		origin  string
+		referer string
+		want    bool
+	}{
+		{name: "cookie post from foreign origin", method: "POST", cookie: true, origin: "https://evil.com", want: false},
+		{name: "cookie delete from foreign referer", method: "DELETE", cookie: true, referer: "https://evil.com/page", want: false},
+		{name: "cookie post from allowed origin", method: "POST", cookie: true, origin: "https://a.x.app", want: true},
+		{name: "cookie post from same host", method: "POST", cookie: true, origin: "https://api.paxeer.app", want: true},
+		{name: "cookie post without origin", method: "POST", cookie: true, want: true},
+		{name: "cookie get from foreign origin", method: "GET", cookie: true, origin: "https://evil.com", want: true},
+		{name: "bearer post from foreign origin", method: "POST", cookie: true, bearer: true, origin: "https://evil.com", want: true},
+		{name: "no cookie post from foreign origin", method: "POST", origin: "https://evil.com", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "https://api.paxeer.app/v1/x", nil)
+			if tt.cookie {
+				r.AddCookie(&http.Cookie{Name: "sid", Value: "s"})
+			}
+			if tt.bearer {
+				r.Header.Set("Authorization", "Bearer key")
+			}
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if tt.referer != "" {
+				r.Header.Set("Referer", tt.referer)
+			}
+			w := httptest.NewRecorder()
+			if got := s.checkCSRF(w, r); got != tt.want {
+				t.Fatalf("checkCSRF = %v, want %v", got, tt.want)
+			}
+			if !tt.want && w.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
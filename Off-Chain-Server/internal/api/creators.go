@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type creatorPool struct {
+	Pool    string     `json:"pool"`
+	Token   string     `json:"token"`
+	Block   int64      `json:"createdBlock"`
+	Tx      string     `json:"createdTx"`
+	Time    *time.Time `json:"createdTime,omitempty"`
+	Name    *string    `json:"name,omitempty"`
+	Symbol  *string    `json:"symbol,omitempty"`
+	LogoURL *string    `json:"logoUrl,omitempty"`
+
+	SpotX18       *string `json:"spotX18"`
+	ProgressPct   *string `json:"progressPct"`
+	MarketCapUSDC *string `json:"marketCapUSDC"`
+	VolumeUSDC    string  `json:"volumeUSDC"`
+	Volume24hUSDC string  `json:"volume24hUSDC"`
+	Trades        int64   `json:"trades"`
+	Holders       int64   `json:"holders"`
+	// Fees: accrued from swaps, collected via CollectCreatorFees, and pending
+	// (read from the pool when RPC is available, else accrued - collected).
+	FeesAccruedUSDC   string `json:"feesAccruedUSDC"`
+	FeesCollectedUSDC string `json:"feesCollectedUSDC"`
+	FeesPendingUSDC   string `json:"feesPendingUSDC"`
+	FeesPendingSource string `json:"feesPendingSource"`
+	// LP depth: real reserves and their USDC value at spot.
+	ReserveUSDC   *string `json:"reserveUSDC"`
+	ReserveToken  *string `json:"reserveToken"`
+	LiquidityUSDC string  `json:"liquidityUSDC"`
+	Comments      int64   `json:"comments"`
+}
+
+// pendingCreatorFees reads pendingCreatorFeesUSDC() from a pool. ok is false without RPC.
+func (s *Server) pendingCreatorFees(ctx context.Context, pool common.Address) (*big.Int, bool, error) {
+	if s.RPC == nil {
+		return nil, false, nil
+	}
+	data, err := s.ABIs.Pool.Pack("pendingCreatorFeesUSDC")
+	if err != nil {
+		return nil, false, err
+	}
+	out, err := s.RPC.CallContract(ctx, ethereum.CallMsg{To: &pool, Data: data}, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	vals, err := s.ABIs.Pool.Unpack("pendingCreatorFeesUSDC", out)
+	if err != nil {
+		return nil, false, err
+	}
+	v, _ := vals[0].(*big.Int)
+	return v, v != nil, nil
+}
+
+// pendingFeeCalls bounds the concurrent pendingCreatorFeesUSDC calls of one request.
+const pendingFeeCalls = 8
+
+// fillPendingCreatorFees sets each pool's pending fees from chain, falling
+// back to the estimate (accrued - collected) without RPC or when the call
+// fails, so one unreachable pool does not fail the dashboard.
+func (s *Server) fillPendingCreatorFees(ctx context.Context, pools []creatorPool) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, pendingFeeCalls)
+	for i := range pools {
+		cp := &pools[i]
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			pending, ok, err := s.pendingCreatorFees(ctx, common.HexToAddress(cp.Pool))
+			if err != nil {
+				log.Printf("[warn] pendingCreatorFeesUSDC %s: %v", cp.Pool, err)
+			}
+			cp.FeesPendingSource = "onchain"
+			if err != nil || !ok {
+				pending = new(big.Int).Sub(bigOrZero(&cp.FeesAccruedUSDC), bigOrZero(&cp.FeesCollectedUSDC))
+				if pending.Sign() < 0 { pending.SetInt64(0) }
+				cp.FeesPendingSource = "estimated"
+			}
+			cp.FeesPendingUSDC = pending.String()
+		}()
+	}
+	wg.Wait()
+}
+
+// GET /creators/{address}/pools?limit=&before=&after=
+func (s *Server) handleCreatorPools(w http.ResponseWriter, r *http.Request) {
+	a := extractBetween(r.URL.Path, "/creators/", "/pools")
+	if !common.IsHexAddress(a) { writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid address"}); return }
+	s.writeCreatorPools(w, r, common.HexToAddress(a).Hex())
+}
+
+// GET /me/pools: the dashboard for the signed-in creator.
+func (s *Server) handleMyPools(w http.ResponseWriter, r *http.Request) {
+	addr, ok := s.requireAuth(w, r)
+	if !ok { return }
+	s.writeCreatorPools(w, r, common.HexToAddress(addr).Hex())
+}
+
+// writeCreatorPools lists the pools whose on-chain creator() is creator,
+// newest first, with trading, fee, liquidity and community figures, plus
+// totals over all of the creator's pools.
+func (s *Server) writeCreatorPools(w http.ResponseWriter, r *http.Request, creator string) {
+	p, err := parsePage(r, 50)
+	if err != nil { writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()}); return }
+	var c pageCursor
+	if p.Cursor != nil { c = *p.Cursor }
+	cond, order, limit, args := p.keyset([]string{"p.created_block", "p.pool_address"}, []any{creator}, c.Block, c.Key)
+	rows, err := s.DB.Query(r.Context(), `
+		SELECT p.pool_address, p.token_address, p.created_block, p.created_tx, p.created_time, m.name, m.symbol, m.logo_url,
+			p.spot_x18::text, p.progress_pct::text, pv.market_cap::text, p.reserve_usdc::text, p.reserve_token::text, pv.tvl::text,
+			COALESCE(v.volume, 0)::text, COALESCE(v.volume_24h, 0)::text, COALESCE(v.trades, 0), COALESCE(v.fees, 0)::text,
+			COALESCE((SELECT SUM(f.amount_usdc) FROM creator_fees f WHERE f.pool_address = p.pool_address), 0)::text,
+			(SELECT COUNT(*) FROM account_positions a WHERE a.pool_address = p.pool_address AND a.token_balance > 0),
+			(SELECT COUNT(*) FROM comments cm WHERE LOWER(cm.pool_address) = LOWER(p.pool_address))
+		FROM pools p
+		JOIN pool_valuations pv ON pv.pool_address = p.pool_address
+		LEFT JOIN pool_metadata m ON m.pool_address = p.pool_address
+		LEFT JOIN LATERAL (
+			SELECT SUM(CASE WHEN s.usdc_to_token THEN s.amount_in ELSE s.amount_out END) AS volume,
+				SUM(CASE WHEN s.usdc_to_token THEN s.amount_in ELSE s.amount_out END) FILTER (WHERE s.block_time >= NOW() - INTERVAL '24 hours') AS volume_24h,
+				COUNT(*) AS trades, SUM(creator_fee_usdc(s.usdc_to_token, s.amount_in, s.amount_out)) AS fees
+			FROM swaps s WHERE s.pool_address = p.pool_address
+		) v ON TRUE
+		WHERE p.creator_address = $1`+cond+` ORDER BY `+order+` LIMIT `+limit, args...)
+	if err != nil { writeErr(w, err); return }
+	out := []creatorPool{}
+	for rows.Next() {
+		var cp creatorPool
+		if err := rows.Scan(&cp.Pool, &cp.Token, &cp.Block, &cp.Tx, &cp.Time, &cp.Name, &cp.Symbol, &cp.LogoURL,
+			&cp.SpotX18, &cp.ProgressPct, &cp.MarketCapUSDC, &cp.ReserveUSDC, &cp.ReserveToken, &cp.LiquidityUSDC,
+			&cp.VolumeUSDC, &cp.Volume24hUSDC, &cp.Trades, &cp.FeesAccruedUSDC, &cp.FeesCollectedUSDC, &cp.Holders, &cp.Comments); err != nil {
+			rows.Close()
+			writeErr(w, err)
+			return
+		}
+		out = append(out, cp)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil { writeErr(w, err); return }
+
+	s.fillPendingCreatorFees(r.Context(), out[:min(len(out), p.Limit)]) // lookahead row is trimmed by pageOf
+
+	var totals struct {
+		Pools             int64  `json:"pools"`
+		VolumeUSDC        string `json:"volumeUSDC"`
+		FeesAccruedUSDC   string `json:"feesAccruedUSDC"`
+		FeesCollectedUSDC string `json:"feesCollectedUSDC"`
+		LiquidityUSDC     string `json:"liquidityUSDC"`
+	}
+	err = s.DB.QueryRow(r.Context(), `
+		SELECT COUNT(*),
+			COALESCE(SUM((SELECT SUM(CASE WHEN s.usdc_to_token THEN s.amount_in ELSE s.amount_out END) FROM swaps s WHERE s.pool_address = p.pool_address)), 0)::text,
+			COALESCE(SUM((SELECT SUM(creator_fee_usdc(s.usdc_to_token, s.amount_in, s.amount_out)) FROM swaps s WHERE s.pool_address = p.pool_address)), 0)::text,
+			COALESCE(SUM((SELECT SUM(f.amount_usdc) FROM creator_fees f WHERE f.pool_address = p.pool_address)), 0)::text,
+			COALESCE(SUM(pv.tvl), 0)::text
+		FROM pools p JOIN pool_valuations pv ON pv.pool_address = p.pool_address
+		WHERE p.creator_address = $1`, creator).
+		Scan(&totals.Pools, &totals.VolumeUSDC, &totals.FeesAccruedUSDC, &totals.FeesCollectedUSDC, &totals.LiquidityUSDC)
+	if err != nil { writeErr(w, err); return }
+
+	page := pageOf(p, out, func(cp creatorPool) pageCursor { return pageCursor{Block: cp.Block, Key: cp.Pool} })
+	writeJSON(w, http.StatusOK, map[string]any{"creator": creator, "totals": totals, "items": page.Items, "nextCursor": page.NextCursor})
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v5"
+)
+
+var symbolPattern = regexp.MustCompile(`^[A-Z]{3,10}$`)
+
+type metadataDraft struct {
+	Token       string     `json:"token"`
+	Creator     string     `json:"creator"`
+	Name        string     `json:"name"`
+	Symbol      string     `json:"symbol"`
+	Description *string    `json:"description,omitempty"`
+	WebsiteURL  *string    `json:"websiteUrl,omitempty"`
+	TwitterURL  *string    `json:"twitterUrl,omitempty"`
+	TelegramURL *string    `json:"telegramUrl,omitempty"`
+	LogoURL     *string    `json:"logoUrl,omitempty"`
+	BannerURL   *string    `json:"bannerUrl,omitempty"`
+	Status      string     `json:"status"`
+	Pool        *string    `json:"pool,omitempty"`
+	PromotedAt  *time.Time `json:"promotedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+const draftColumns = `token_address, creator, name, symbol, description, website_url, twitter_url, telegram_url, logo_url, banner_url, status, pool_address, promoted_at, created_at, updated_at`
+
+func scanDraft(row pgx.Row) (metadataDraft, error) {
+	var d metadataDraft
+	err := row.Scan(&d.Token, &d.Creator, &d.Name, &d.Symbol, &d.Description, &d.WebsiteURL, &d.TwitterURL, &d.TelegramURL, &d.LogoURL, &d.BannerURL, &d.Status, &d.Pool, &d.PromotedAt, &d.CreatedAt, &d.UpdatedAt)
+	return d, err
+}
+
+// draftTarget authenticates the caller and parses /drafts/{token}.
+func (s *Server) draftTarget(w http.ResponseWriter, r *http.Request) (token, creator string, ok bool) {
+	addr, ok := s.requireAuth(w, r)
+	if !ok { return "", "", false }
+	t := strings.TrimPrefix(r.URL.Path, "/drafts/")
+	if !common.IsHexAddress(t) {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid token address"})
+		return "", "", false
+	}
+	return common.HexToAddress(t).Hex(), common.HexToAddress(addr).Hex(), true
+}
+
+// GET /drafts: the signed-in wallet's drafts, newest first.
+func (s *Server) handleListDrafts(w http.ResponseWriter, r *http.Request) {
+	addr, ok := s.requireAuth(w, r)
+	if !ok { return }
+	rows, err := s.DB.Query(r.Context(), `SELECT `+draftColumns+` FROM metadata_drafts WHERE creator = $1 ORDER BY updated_at DESC`, common.HexToAddress(addr).Hex())
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	out := []metadataDraft{}
+	for rows.Next() {
+		d, err := scanDraft(rows)
+		if err != nil { writeErr(w, err); return }
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil { writeErr(w, err); return }
+	writeJSON(w, http.StatusOK, out)
+}
+
+// GET /drafts/{token}
+func (s *Server) handleGetDraft(w http.ResponseWriter, r *http.Request) {
+	token, creator, ok := s.draftTarget(w, r)
+	if !ok { return }
+	d, err := scanDraft(s.DB.QueryRow(r.Context(), `SELECT `+draftColumns+` FROM metadata_drafts WHERE token_address = $1 AND creator = $2`, token, creator))
+	if err == pgx.ErrNoRows { writeJSON(w, http.StatusNotFound, map[string]any{"error": "draft not found"}); return }
+	if err != nil { writeErr(w, err); return }
+	writeJSON(w, http.StatusOK, d)
+}
+
+// PUT /drafts/{token}
+// Creates or replaces the caller's draft for a token that has no pool yet.
+// The indexer promotes it to pool_metadata when the caller creates the pool.
+func (s *Server) handlePutDraft(w http.ResponseWriter, r *http.Request) {
+	token, creator, ok := s.draftTarget(w, r)
+	if !ok { return }
+	var in struct {
+		Name        string  `json:"name"`
+		Symbol      string  `json:"symbol"`
+		Description *string `json:"description"`
+		WebsiteURL  *string `json:"websiteUrl"`
+		TwitterURL  *string `json:"twitterUrl"`
+		TelegramURL *string `json:"telegramUrl"`
+		LogoURL     *string `json:"logoUrl"`
+		BannerURL   *string `json:"bannerUrl"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil { writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid body"}); return }
+	if in.Name == "" || len(in.Name) > 50 { writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid name"}); return }
+	if !symbolPattern.MatchString(in.Symbol) { writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid symbol"}); return }
+	if in.Description != nil && len(*in.Description) > 500 { writeJSON(w, http.StatusBadRequest, map[string]any{"error": "description too long"}); return }
+
+	var pool string
+	err := s.DB.QueryRow(r.Context(), `SELECT pool_address FROM pools WHERE token_address = $1 LIMIT 1`, token).Scan(&pool)
+	if err == nil {
+		writeJSON(w, http.StatusConflict, map[string]any{"error": "token already has a pool; edit its metadata instead", "pool": pool})
+		return
+	}
+	if err != pgx.ErrNoRows { writeErr(w, err); return }
+
+	d, err := scanDraft(s.DB.QueryRow(r.Context(), `
+		INSERT INTO metadata_drafts(token_address, creator, name, symbol, description, website_url, twitter_url, telegram_url, logo_url, banner_url)
+		VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+		ON CONFLICT(token_address, creator) DO UPDATE SET name = EXCLUDED.name, symbol = EXCLUDED.symbol, description = EXCLUDED.description,
+			website_url = EXCLUDED.website_url, twitter_url = EXCLUDED.twitter_url, telegram_url = EXCLUDED.telegram_url,
+			logo_url = EXCLUDED.logo_url, banner_url = EXCLUDED.banner_url,
+			status = 'pending', pool_address = NULL, promoted_at = NULL, updated_at = NOW()
+		RETURNING `+draftColumns,
+		token, creator, in.Name, in.Symbol, in.Description, in.WebsiteURL, in.TwitterURL, in.TelegramURL, in.LogoURL, in.BannerURL))
+	if err != nil { writeErr(w, err); return }
+	writeJSON(w, http.StatusOK, d)
+}
+
+// DELETE /drafts/{token}
+func (s *Server) handleDeleteDraft(w http.ResponseWriter, r *http.Request) {
+	token, creator, ok := s.draftTarget(w, r)
+	if !ok { return }
+	tag, err := s.DB.Exec(r.Context(), `DELETE FROM metadata_drafts WHERE token_address = $1 AND creator = $2`, token, creator)
+	if err != nil { writeErr(w, err); return }
+	if tag.RowsAffected() == 0 { writeJSON(w, http.StatusNotFound, map[string]any{"error": "draft not found"}); return }
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v5"
+)
+
+var errCreatorUnknown = errors.New("pool creator not known yet")
+
+// poolAuthority is what metadata writes are checked against: the pool's
+// token and on-chain creator, never values supplied by the caller.
+type poolAuthority struct {
+	Pool, Token, Creator string
+}
+
+// poolAuthority resolves a pool's token and creator from the pools table,
+// falling back to LaunchPool.creator() over RPC when the indexer has not
+// recorded the creator yet. It returns pgx.ErrNoRows for unknown pools.
+func (s *Server) poolAuthority(ctx context.Context, pool string) (*poolAuthority, error) {
+	if !common.IsHexAddress(pool) {
+		return nil, pgx.ErrNoRows
+	}
+	pa := &poolAuthority{Pool: common.HexToAddress(pool).Hex()}
+	var creator *string
+	if err := s.DB.QueryRow(ctx, `SELECT token_address, creator_address FROM pools WHERE pool_address = $1`, pa.Pool).Scan(&pa.Token, &creator); err != nil {
+		return nil, err
+	}
+	if creator != nil && *creator != "" {
+		pa.Creator = *creator
+		return pa, nil
+	}
+	if s.RPC == nil {
+		return nil, errCreatorUnknown
+	}
+	data, err := s.ABIs.Pool.Pack("creator")
+	if err != nil {
+		return nil, err
+	}
+	to := common.HexToAddress(pa.Pool)
+	out, err := s.RPC.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	vals, err := s.ABIs.Pool.Unpack("creator", out)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := vals[0].(common.Address)
+	if !ok || c == (common.Address{}) {
+		return nil, errCreatorUnknown
+	}
+	pa.Creator = c.Hex()
+	return pa, nil
+}
+
+func (pa *poolAuthority) isCreator(addr string) bool { return strings.EqualFold(pa.Creator, addr) }
+
+// canEdit reports whether addr is the creator or one of its delegated editors.
+func (s *Server) canEdit(ctx context.Context, pa *poolAuthority, addr string) (bool, error) {
+	if pa.isCreator(addr) {
+		return true, nil
+	}
+	var ok bool
+	err := s.DB.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM pool_metadata_editors WHERE pool_address = $1 AND editor = $2)`, pa.Pool, common.HexToAddress(addr).Hex()).Scan(&ok)
+	return ok, err
+}
+
+// loadPoolAuthority writes the error response for poolAuthority failures.
+func (s *Server) loadPoolAuthority(w http.ResponseWriter, r *http.Request, pool string) (*poolAuthority, bool) {
+	pa, err := s.poolAuthority(r.Context(), pool)
+	switch {
+	case err == pgx.ErrNoRows:
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "pool not found"})
+	case err == errCreatorUnknown:
+		writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
+	case err != nil:
+		writeErr(w, err)
+	default:
+		return pa, true
+	}
+	return nil, false
+}
+
+// requirePoolEditor authenticates the caller and checks they may edit the pool's metadata.
+func (s *Server) requirePoolEditor(w http.ResponseWriter, r *http.Request, pool string) (*poolAuthority, string, bool) {
+	addr, ok := s.requireAuth(w, r)
+	if !ok { return nil, "", false }
+	pa, ok := s.loadPoolAuthority(w, r, pool)
+	if !ok { return nil, "", false }
+	allowed, err := s.canEdit(r.Context(), pa, addr)
+	if err != nil { writeErr(w, err); return nil, "", false }
+	if !allowed {
+		writeJSON(w, http.StatusForbidden, map[string]any{"error": "only the pool creator or its editors may edit metadata"})
+		return nil, "", false
+	}
+	return pa, common.HexToAddress(addr).Hex(), true
+}
+
+// requirePoolCreator authenticates the caller and checks they are the pool's creator.
+func (s *Server) requirePoolCreator(w http.ResponseWriter, r *http.Request, pool string) (*poolAuthority, bool) {
+	addr, ok := s.requireAuth(w, r)
+	if !ok { return nil, false }
+	pa, ok := s.loadPoolAuthority(w, r, pool)
+	if !ok { return nil, false }
+	if !pa.isCreator(addr) {
+		writeJSON(w, http.StatusForbidden, map[string]any{"error": "only the pool creator may manage editors"})
+		return nil, false
+	}
+	return pa, true
+}
+
+// GET /pools/{pool}/editors
+func (s *Server) handleListPoolEditors(w http.ResponseWriter, r *http.Request) {
+	pa, ok := s.loadPoolAuthority(w, r, extractBetween(r.URL.Path, "/pools/", "/editors"))
+	if !ok { return }
+	rows, err := s.DB.Query(r.Context(), `SELECT editor, granted_by, created_at FROM pool_metadata_editors WHERE pool_address = $1 ORDER BY created_at ASC`, pa.Pool)
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	type editor struct {
+		Address   string    `json:"address"`
+		GrantedBy string    `json:"grantedBy"`
+		CreatedAt time.Time `json:"createdAt"`
+	}
+	out := []editor{}
+	for rows.Next() {
+		var e editor
+		if err := rows.Scan(&e.Address, &e.GrantedBy, &e.CreatedAt); err != nil { writeErr(w, err); return }
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil { writeErr(w, err); return }
+	writeJSON(w, http.StatusOK, map[string]any{"pool": pa.Pool, "creator": pa.Creator, "editors": out})
+}
+
+// POST /pools/{pool}/editors {"address"} (creator only)
+func (s *Server) handleAddPoolEditor(w http.ResponseWriter, r *http.Request) {
+	pa, ok := s.requirePoolCreator(w, r, extractBetween(r.URL.Path, "/pools/", "/editors"))
+	if !ok { return }
+	var in struct{ Address string `json:"address"` }
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil || !common.IsHexAddress(in.Address) {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid address"})
+		return
+	}
+	editor := common.HexToAddress(in.Address).Hex()
+	if pa.isCreator(editor) { writeJSON(w, http.StatusBadRequest, map[string]any{"error": "creator can already edit"}); return }
+	_, err := s.DB.Exec(r.Context(), `INSERT INTO pool_metadata_editors(pool_address, editor, granted_by) VALUES($1,$2,$3) ON CONFLICT DO NOTHING`, pa.Pool, editor, pa.Creator)
+	if err != nil { writeErr(w, err); return }
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "editor": editor})
+}
+
+// DELETE /pools/{pool}/editors/{address} (creator only)
+func (s *Server) handleRemovePoolEditor(w http.ResponseWriter, r *http.Request) {
+	i := strings.LastIndex(r.URL.Path, "/editors/")
+	editor := r.URL.Path[i+len("/editors/"):]
+	if !common.IsHexAddress(editor) { writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid address"}); return }
+	pa, ok := s.requirePoolCreator(w, r, extractBetween(r.URL.Path[:i+len("/editors")], "/pools/", "/editors"))
+	if !ok { return }
+	tag, err := s.DB.Exec(r.Context(), `DELETE FROM pool_metadata_editors WHERE pool_address = $1 AND editor = $2`, pa.Pool, common.HexToAddress(editor).Hex())
+	if err != nil { writeErr(w, err); return }
+	if tag.RowsAffected() == 0 { writeJSON(w, http.StatusNotFound, map[string]any{"error": "not an editor"}); return }
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+}
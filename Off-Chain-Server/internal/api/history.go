@@ -0,0 +1,181 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// eventRange holds the optional fromBlock/toBlock/from/to filters shared by
+// the per-pool event history endpoints.
+type eventRange struct {
+	FromBlock, ToBlock *int64
+	From, To           *time.Time
+}
+
+func parseEventRange(r *http.Request) (eventRange, error) {
+	var er eventRange
+	q := r.URL.Query()
+	for _, p := range []struct {
+		name string
+		dst  **int64
+	}{{"fromBlock", &er.FromBlock}, {"toBlock", &er.ToBlock}} {
+		if v := q.Get(p.name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return er, fmt.Errorf("invalid %s", p.name)
+			}
+			*p.dst = &n
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &er.From}, {"to", &er.To}} {
+		if v := q.Get(p.name); v != "" {
+			t, ok := parseTimeParam(v)
+			if !ok {
+				return er, fmt.Errorf("invalid %s", p.name)
+			}
+			*p.dst = &t
+		}
+	}
+	return er, nil
+}
+
+// clause appends the range conditions to a query whose existing arguments are args.
+func (er eventRange) clause(args []any) (string, []any) {
+	var sb strings.Builder
+	add := func(cond string, v any) {
+		args = append(args, v)
+		fmt.Fprintf(&sb, " AND %s $%d", cond, len(args))
+	}
+	if er.FromBlock != nil { add("block_number >=", *er.FromBlock) }
+	if er.ToBlock != nil { add("block_number <=", *er.ToBlock) }
+	if er.From != nil { add("block_time >=", *er.From) }
+	if er.To != nil { add("block_time <=", *er.To) }
+	return sb.String(), args
+}
+
+// eventPage parses the range filters and page cursor shared by the history
+// endpoints and returns the WHERE suffix, ORDER BY and LIMIT for a query
+// whose only argument so far is the pool address.
+func eventPage(r *http.Request, pool string) (pageRequest, string, []any, error) {
+	er, err := parseEventRange(r)
+	if err != nil { return pageRequest{}, "", nil, err }
+	p, err := parsePage(r, 100)
+	if err != nil { return p, "", nil, err }
+	cond, args := er.clause([]any{pool})
+	var c pageCursor
+	if p.Cursor != nil { c = *p.Cursor }
+	kc, order, limit, args := p.keyset([]string{"block_number", "log_index"}, args, c.Block, c.Log)
+	return p, cond + kc + " ORDER BY " + order + " LIMIT " + limit, args, nil
+}
+
+func eventCursor(block int64, logIndex int) pageCursor {
+	return pageCursor{Block: block, Log: int64(logIndex)}
+}
+
+// GET /pools/{pool}/liquidity?fromBlock=&toBlock=&from=&to=&limit=&before=&after=
+func (s *Server) handleLiquidityEvents(w http.ResponseWriter, r *http.Request) {
+	pool := extractBetween(r.URL.Path, "/pools/", "/liquidity")
+	p, tail, args, err := eventPage(r, pool)
+	if err != nil { writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()}); return }
+	rows, err := s.DB.Query(r.Context(), `SELECT event_type, provider, amount_usdc, amount_token, lp_amount, block_number, tx_hash, log_index, block_time FROM liquidity_events WHERE pool_address = $1`+tail, args...)
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	type row struct{
+		EventType string `json:"eventType"`
+		Provider string `json:"provider"`
+		AmountUSDC string `json:"amountUSDC"`
+		AmountToken string `json:"amountToken"`
+		LPAmount *string `json:"lpAmount,omitempty"`
+		Block int64 `json:"blockNumber"`
+		Tx string `json:"txHash"`
+		LogIndex int `json:"logIndex"`
+		Time *time.Time `json:"blockTime,omitempty"`
+	}
+	out := []row{}
+	for rows.Next() {
+		var rr row
+		_ = rows.Scan(&rr.EventType, &rr.Provider, &rr.AmountUSDC, &rr.AmountToken, &rr.LPAmount, &rr.Block, &rr.Tx, &rr.LogIndex, &rr.Time)
+		out = append(out, rr)
+	}
+	writeJSON(w, http.StatusOK, pageOf(p, out, func(rr row) pageCursor { return eventCursor(rr.Block, rr.LogIndex) }))
+}
+
+// GET /pools/{pool}/oracle-updates?fromBlock=&toBlock=&from=&to=&limit=&before=&after=
+func (s *Server) handleOracleUpdates(w http.ResponseWriter, r *http.Request) {
+	pool := extractBetween(r.URL.Path, "/pools/", "/oracle-updates")
+	p, tail, args, err := eventPage(r, pool)
+	if err != nil { writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()}); return }
+	rows, err := s.DB.Query(r.Context(), `SELECT price_cumulative, oracle_timestamp, block_number, tx_hash, log_index, block_time FROM oracle_updates WHERE pool_address = $1`+tail, args...)
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	type row struct{
+		PriceCumulative string `json:"priceCumulative"`
+		OracleTimestamp int64 `json:"oracleTimestamp"`
+		Block int64 `json:"blockNumber"`
+		Tx string `json:"txHash"`
+		LogIndex int `json:"logIndex"`
+		Time *time.Time `json:"blockTime,omitempty"`
+	}
+	out := []row{}
+	for rows.Next() {
+		var rr row
+		_ = rows.Scan(&rr.PriceCumulative, &rr.OracleTimestamp, &rr.Block, &rr.Tx, &rr.LogIndex, &rr.Time)
+		out = append(out, rr)
+	}
+	writeJSON(w, http.StatusOK, pageOf(p, out, func(rr row) pageCursor { return eventCursor(rr.Block, rr.LogIndex) }))
+}
+
+// GET /pools/{pool}/creator-fees?fromBlock=&toBlock=&from=&to=&limit=&before=&after=
+func (s *Server) handleCreatorFees(w http.ResponseWriter, r *http.Request) {
+	pool := extractBetween(r.URL.Path, "/pools/", "/creator-fees")
+	p, tail, args, err := eventPage(r, pool)
+	if err != nil { writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()}); return }
+	rows, err := s.DB.Query(r.Context(), `SELECT amount_usdc, block_number, tx_hash, log_index, block_time FROM creator_fees WHERE pool_address = $1`+tail, args...)
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	type row struct{
+		AmountUSDC string `json:"amountUSDC"`
+		Block int64 `json:"blockNumber"`
+		Tx string `json:"txHash"`
+		LogIndex int `json:"logIndex"`
+		Time *time.Time `json:"blockTime,omitempty"`
+	}
+	out := []row{}
+	for rows.Next() {
+		var rr row
+		_ = rows.Scan(&rr.AmountUSDC, &rr.Block, &rr.Tx, &rr.LogIndex, &rr.Time)
+		out = append(out, rr)
+	}
+	writeJSON(w, http.StatusOK, pageOf(p, out, func(rr row) pageCursor { return eventCursor(rr.Block, rr.LogIndex) }))
+}
+
+// GET /pools/{pool}/reserves?fromBlock=&toBlock=&from=&to=&limit=&before=&after=
+func (s *Server) handleReserves(w http.ResponseWriter, r *http.Request) {
+	pool := extractBetween(r.URL.Path, "/pools/", "/reserves")
+	p, tail, args, err := eventPage(r, pool)
+	if err != nil { writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()}); return }
+	rows, err := s.DB.Query(r.Context(), `SELECT reserve_usdc, reserve_token, block_number, tx_hash, log_index, block_time FROM reserves WHERE pool_address = $1`+tail, args...)
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	type row struct{
+		ReserveUSDC string `json:"reserveUSDC"`
+		ReserveToken string `json:"reserveToken"`
+		Block int64 `json:"blockNumber"`
+		Tx string `json:"txHash"`
+		LogIndex int `json:"logIndex"`
+		Time *time.Time `json:"blockTime,omitempty"`
+	}
+	out := []row{}
+	for rows.Next() {
+		var rr row
+		_ = rows.Scan(&rr.ReserveUSDC, &rr.ReserveToken, &rr.Block, &rr.Tx, &rr.LogIndex, &rr.Time)
+		out = append(out, rr)
+	}
+	writeJSON(w, http.StatusOK, pageOf(p, out, func(rr row) pageCursor { return eventCursor(rr.Block, rr.LogIndex) }))
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/paxeer/offchain-server/internal/indexer"
+)
+
+// leaderboard describes one board: its rollup table, the ranked entity
+// column, and the columns it can be sorted by (first is the default).
+type leaderboard struct {
+	table, key string
+	sorts      map[string]string
+	defSort    string
+}
+
+var leaderboards = map[string]leaderboard{
+	"traders":  {table: "leaderboard_traders", key: "trader", defSort: "volume", sorts: map[string]string{"volume": "volume_usdc", "pnl": "realized_pnl_usdc"}},
+	"creators": {table: "leaderboard_creators", key: "creator", defSort: "fees", sorts: map[string]string{"fees": "fees_usdc", "volume": "volume_usdc"}},
+	"pools":    {table: "leaderboard_pools", key: "pool_address", defSort: "volume", sorts: map[string]string{"volume": "volume_usdc", "holderGrowth": "new_holders"}},
+}
+
+func validPeriod(v string) bool {
+	for _, p := range indexer.LeaderboardPeriods {
+		if p.Name == v { return true }
+	}
+	return false
+}
+
+type leaderboardEntry struct {
+	Rank    int64  `json:"rank"`
+	Address string `json:"address"`
+	// Traders and creators
+	Username  *string `json:"username,omitempty"`
+	AvatarURL *string `json:"avatarUrl,omitempty"`
+	// Pools
+	Token   *string `json:"token,omitempty"`
+	Name    *string `json:"name,omitempty"`
+	Symbol  *string `json:"symbol,omitempty"`
+	LogoURL *string `json:"logoUrl,omitempty"`
+
+	VolumeUSDC      string  `json:"volumeUSDC"`
+	Trades          int64   `json:"trades"`
+	RealizedPnlUSDC *string `json:"realizedPnlUSDC,omitempty"`
+	FeesUSDC        *string `json:"feesUSDC,omitempty"`
+	FeesCollected   *string `json:"feesCollectedUSDC,omitempty"`
+	Pools           *int64  `json:"pools,omitempty"`
+	Traders         *int64  `json:"traders,omitempty"`
+	NewHolders      *int64  `json:"newHolders,omitempty"`
+	Holders         *int64  `json:"holders,omitempty"`
+}
+
+// GET /leaderboards/{traders|creators|pools}?window=24h|7d|all&sort=&limit=&before=
+// Reads the rollups refreshed by the indexer; refreshedAt reports their age.
+func (s *Server) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	bad := func(msg string) { writeJSON(w, http.StatusBadRequest, map[string]any{"error": msg}) }
+	name := strings.TrimPrefix(r.URL.Path, "/leaderboards/")
+	lb, ok := leaderboards[name]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "unknown leaderboard"})
+		return
+	}
+	q := r.URL.Query()
+	window := q.Get("window")
+	if window == "" { window = "24h" }
+	if !validPeriod(window) { bad("invalid window"); return }
+	sort := q.Get("sort")
+	if sort == "" { sort = lb.defSort }
+	sortCol, ok := lb.sorts[sort]
+	if !ok { bad("invalid sort"); return }
+	p, err := parsePage(r, 50)
+	if err != nil { bad(err.Error()); return }
+	if p.After { bad("after is not supported for leaderboards"); return }
+	var c pageCursor
+	if p.Cursor != nil { c = *p.Cursor }
+
+	var cols, joins string
+	switch name {
+	case "traders":
+		cols = `NULL::text, NULL::text, NULL::text, NULL::text, pr.username, pr.avatar_url, l.realized_pnl_usdc::text, NULL::text, NULL::text, l.pools::bigint, NULL::bigint, NULL::bigint, NULL::bigint`
+		joins = `LEFT JOIN profiles pr ON pr.address = LOWER(l.trader)`
+	case "creators":
+		cols = `NULL::text, NULL::text, NULL::text, NULL::text, pr.username, pr.avatar_url, NULL::text, l.fees_usdc::text, l.fees_collected_usdc::text, l.pools::bigint, NULL::bigint, NULL::bigint, NULL::bigint`
+		joins = `LEFT JOIN profiles pr ON pr.address = LOWER(l.creator)`
+	case "pools":
+		cols = `p.token_address, m.name, m.symbol, m.logo_url, NULL::text, NULL::text, NULL::text, NULL::text, NULL::text, NULL::bigint, l.traders::bigint, l.new_holders::bigint, l.holders::bigint`
+		joins = `JOIN pools p ON p.pool_address = l.pool_address LEFT JOIN pool_metadata m ON m.pool_address = l.pool_address`
+	}
+	rows, err := s.DB.Query(r.Context(), `
+		SELECT l.`+lb.key+`, l.volume_usdc::text, l.trades::bigint, l.refreshed_at, `+cols+`
+		FROM `+lb.table+` l `+joins+`
+		WHERE l.period = $1
+		ORDER BY l.`+sortCol+` DESC, l.`+lb.key+` ASC
+		LIMIT $2 OFFSET $3`, window, p.Limit+1, c.Offset)
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	var refreshedAt *time.Time
+	out := []leaderboardEntry{}
+	for rows.Next() {
+		var (
+			e  leaderboardEntry
+			at time.Time
+		)
+		if err := rows.Scan(&e.Address, &e.VolumeUSDC, &e.Trades, &at, &e.Token, &e.Name, &e.Symbol, &e.LogoURL, &e.Username, &e.AvatarURL,
+			&e.RealizedPnlUSDC, &e.FeesUSDC, &e.FeesCollected, &e.Pools, &e.Traders, &e.NewHolders, &e.Holders); err != nil {
+			writeErr(w, err)
+			return
+		}
+		e.Rank = c.Offset + int64(len(out)) + 1
+		refreshedAt = &at
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil { writeErr(w, err); return }
+	page := pageOf(p, out, func(leaderboardEntry) pageCursor { return pageCursor{Offset: c.Offset + int64(p.Limit)} })
+	writeJSON(w, http.StatusOK, map[string]any{
+		"board": name, "window": window, "sort": sort, "refreshedAt": refreshedAt,
+		"items": page.Items, "nextCursor": page.NextCursor,
+	})
+}
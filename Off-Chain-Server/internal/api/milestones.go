@@ -0,0 +1,144 @@
+package api
+
+import (
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/paxeer/offchain-server/internal/config"
+	"github.com/paxeer/offchain-server/internal/indexer"
+)
+
+type milestoneCrossing struct {
+	Name       string     `json:"name"`
+	Kind       string     `json:"kind"`
+	Threshold  string     `json:"threshold"`
+	Graduation bool       `json:"graduation"`
+	Block      int64      `json:"blockNumber"`
+	Tx         string     `json:"txHash"`
+	Time       *time.Time `json:"blockTime,omitempty"`
+}
+
+type milestoneProgress struct {
+	Name         string             `json:"name"`
+	Kind         string             `json:"kind"`
+	Threshold    string             `json:"threshold"`
+	Graduation   bool               `json:"graduation"`
+	CurrentValue string             `json:"currentValue"`
+	ProgressPct  string             `json:"progressPct"`
+	Reached      *milestoneCrossing `json:"reached"`
+}
+
+// milestonesForPool returns the configured milestones for the pool's factory;
+// pools indexed before factories were recorded use the configured factory.
+func (s *Server) milestonesForPool(factory *string) []config.Milestone {
+	if s.Config == nil {
+		return nil
+	}
+	f := s.Config.Contracts.Factory
+	if factory != nil && *factory != "" {
+		f = *factory
+	}
+	return s.Config.MilestonesFor(f)
+}
+
+// GET /pools/{pool}/progress
+// Bonding-curve progress toward the graduation milestone, each configured
+// milestone's live progress, and the recorded crossing history.
+func (s *Server) handlePoolProgress(w http.ResponseWriter, r *http.Request) {
+	pool := extractBetween(r.URL.Path, "/pools/", "/progress")
+	var (
+		factory, progress   *string
+		realStr, marketCapS string
+	)
+	err := s.DB.QueryRow(r.Context(), `
+		SELECT p.factory_address, p.progress_pct, COALESCE(p.reserve_usdc, 0)::text, COALESCE(pv.market_cap, 0)::text
+		FROM pools p JOIN pool_valuations pv ON pv.pool_address = p.pool_address
+		WHERE p.pool_address = $1`, pool).Scan(&factory, &progress, &realStr, &marketCapS)
+	if err == pgx.ErrNoRows {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "pool not found"})
+		return
+	}
+	if err != nil { writeErr(w, err); return }
+	realUSDC, _ := new(big.Int).SetString(realStr, 10)
+	marketCap, _ := new(big.Int).SetString(marketCapS, 10)
+
+	rows, err := s.DB.Query(r.Context(), `SELECT name, kind, threshold::text, graduation, block_number, tx_hash, block_time FROM pool_milestones WHERE pool_address = $1 ORDER BY block_number ASC, name ASC`, pool)
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	history := []milestoneCrossing{}
+	reached := map[string]*milestoneCrossing{}
+	for rows.Next() {
+		var c milestoneCrossing
+		if err := rows.Scan(&c.Name, &c.Kind, &c.Threshold, &c.Graduation, &c.Block, &c.Tx, &c.Time); err != nil { writeErr(w, err); return }
+		history = append(history, c)
+	}
+	if err := rows.Err(); err != nil { writeErr(w, err); return }
+	for i := range history {
+		reached[history[i].Name] = &history[i]
+	}
+
+	configured := s.milestonesForPool(factory)
+	milestones := []milestoneProgress{}
+	for _, m := range configured {
+		th, err := m.ThresholdUnits(s.usdcDecimals())
+		if err != nil { writeErr(w, err); return }
+		v := indexer.MilestoneValue(m.Kind, realUSDC, marketCap)
+		milestones = append(milestones, milestoneProgress{
+			Name: m.Name, Kind: m.Kind, Threshold: th.String(), Graduation: m.Graduation,
+			CurrentValue: v.String(), ProgressPct: indexer.ProgressPct(v, th), Reached: reached[m.Name],
+		})
+	}
+
+	resp := map[string]any{"pool": pool, "progressPct": progress, "graduated": false, "milestones": milestones, "history": history}
+	if grad, ok := indexer.GraduationMilestone(configured); ok {
+		if c := reached[grad.Name]; c != nil {
+			resp["graduated"] = true
+			resp["graduatedAt"] = c
+		}
+		if progress == nil {
+			// Not computed by the indexer yet (no Sync since milestones were configured).
+			for _, m := range milestones {
+				if m.Name == grad.Name { resp["progressPct"] = m.ProgressPct }
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GET /pools/graduated?limit=&before=&after=
+// Pools that crossed their graduation milestone, most recent first.
+func (s *Server) handleGraduatedPools(w http.ResponseWriter, r *http.Request) {
+	p, err := parsePage(r, 50)
+	if err != nil { writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()}); return }
+	var c pageCursor
+	if p.Cursor != nil { c = *p.Cursor }
+	cond, order, limit, args := p.keyset([]string{"g.block_number", "g.pool_address"}, nil, c.Block, c.Key)
+	rows, err := s.DB.Query(r.Context(), `
+		SELECT g.pool_address, p.token_address, g.name, g.block_number, g.tx_hash, g.block_time, m.name, m.symbol, m.logo_url
+		FROM pool_milestones g
+		JOIN pools p ON p.pool_address = g.pool_address
+		LEFT JOIN pool_metadata m ON m.pool_address = g.pool_address
+		WHERE g.graduation`+cond+` ORDER BY `+order+` LIMIT `+limit, args...)
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	type row struct {
+		Pool      string     `json:"pool"`
+		Token     string     `json:"token"`
+		Milestone string     `json:"milestone"`
+		Block     int64      `json:"blockNumber"`
+		Tx        string     `json:"txHash"`
+		Time      *time.Time `json:"blockTime,omitempty"`
+		Name      *string    `json:"name,omitempty"`
+		Symbol    *string    `json:"symbol,omitempty"`
+		LogoURL   *string    `json:"logoUrl,omitempty"`
+	}
+	out := []row{}
+	for rows.Next() {
+		var rr row
+		if err := rows.Scan(&rr.Pool, &rr.Token, &rr.Milestone, &rr.Block, &rr.Tx, &rr.Time, &rr.Name, &rr.Symbol, &rr.LogoURL); err != nil { writeErr(w, err); return }
+		out = append(out, rr)
+	}
+	writeJSON(w, http.StatusOK, pageOf(p, out, func(rr row) pageCursor { return pageCursor{Block: rr.Block, Key: rr.Pool} }))
+}
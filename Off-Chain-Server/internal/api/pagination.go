@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxPageLimit caps the page size of every list endpoint.
+const maxPageLimit = 500
+
+// pageCursor is the position behind an opaque before/after cursor. Event
+// lists key on (Block, Log), comments on ID and pools on (Block, Key).
+// Lists ordered by computed values page by Offset instead.
+type pageCursor struct {
+	Block  int64  `json:"b,omitempty"`
+	Log    int64  `json:"l,omitempty"`
+	ID     int64  `json:"i,omitempty"`
+	Key    string `json:"k,omitempty"`
+	Offset int64  `json:"o,omitempty"`
+}
+
+func encodeCursor(c pageCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	var c pageCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, errors.New("invalid cursor")
+	}
+	if err := json.Unmarshal(b, &c); err != nil || c.Offset < 0 {
+		return c, errors.New("invalid cursor")
+	}
+	return c, nil
+}
+
+// pageRequest is a parsed ?limit=&before=&after= query. Items are always
+// returned newest first; "before" pages toward older rows and "after"
+// toward newer ones.
+type pageRequest struct {
+	Limit  int
+	Cursor *pageCursor
+	After  bool
+}
+
+func parsePage(r *http.Request, defaultLimit int) (pageRequest, error) {
+	q := r.URL.Query()
+	p := pageRequest{Limit: parseIntDefault(q.Get("limit"), defaultLimit)}
+	if p.Limit <= 0 {
+		p.Limit = defaultLimit
+	}
+	if p.Limit > maxPageLimit {
+		p.Limit = maxPageLimit
+	}
+	before, after := q.Get("before"), q.Get("after")
+	if before != "" && after != "" {
+		return p, errors.New("use either before or after")
+	}
+	raw := before
+	if after != "" {
+		raw, p.After = after, true
+	}
+	if raw != "" {
+		c, err := decodeCursor(raw)
+		if err != nil {
+			return p, err
+		}
+		p.Cursor = &c
+	}
+	return p, nil
+}
+
+// keyset returns the cursor condition (prefixed with AND), the ORDER BY list
+// and the LIMIT placeholder for a page over the key columns, appending the
+// cursor values and limit+1 to args.
+func (p pageRequest) keyset(cols []string, args []any, vals ...any) (cond, order, limit string, outArgs []any) {
+	dir, op := "DESC", "<"
+	if p.After {
+		dir, op = "ASC", ">"
+	}
+	if p.Cursor != nil {
+		ph := make([]string, len(vals))
+		for i, v := range vals {
+			args = append(args, v)
+			ph[i] = fmt.Sprintf("$%d", len(args))
+		}
+		cond = fmt.Sprintf(" AND (%s) %s (%s)", strings.Join(cols, ", "), op, strings.Join(ph, ", "))
+	}
+	ord := make([]string, len(cols))
+	for i, c := range cols {
+		ord[i] = c + " " + dir
+	}
+	args = append(args, p.Limit+1)
+	return cond, strings.Join(ord, ", "), fmt.Sprintf("$%d", len(args)), args
+}
+
+type pageResponse struct {
+	Items      any     `json:"items"`
+	NextCursor *string `json:"nextCursor"`
+}
+
+// pageOf trims the extra probe row, restores newest-first order and builds
+// the cursor that continues in the requested direction.
+func pageOf[T any](p pageRequest, items []T, key func(T) pageCursor) pageResponse {
+	more := len(items) > p.Limit
+	if more {
+		items = items[:p.Limit]
+	}
+	if p.After {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	resp := pageResponse{Items: items}
+	if more && len(items) > 0 {
+		edge := items[len(items)-1]
+		if p.After {
+			edge = items[0]
+		}
+		c := encodeCursor(key(edge))
+		resp.NextCursor = &c
+	}
+	return resp
+}
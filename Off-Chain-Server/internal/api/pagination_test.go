@@ -0,0 +1,167 @@
+package api
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParsePageLimit(t *testing.T) {
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 100},
+		{"limit=25", 25},
+		{"limit=0", 100},
+		{"limit=-5", 100},
+		{"limit=abc", 100},
+		{"limit=501", maxPageLimit},
+		{"limit=500", 500},
+	}
+	for _, tt := range tests {
+		p, err := parsePage(httptest.NewRequest("GET", "/x?"+tt.query, nil), 100)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.query, err)
+		}
+		if p.Limit != tt.want {
+			t.Errorf("%q: limit = %d, want %d", tt.query, p.Limit, tt.want)
+		}
+	}
+}
+
+func TestParsePageCursor(t *testing.T) {
+	c := encodeCursor(pageCursor{Block: 7, Log: 3})
+	p, err := parsePage(httptest.NewRequest("GET", "/x?after="+c, nil), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.After || p.Cursor == nil || *p.Cursor != (pageCursor{Block: 7, Log: 3}) {
+		t.Errorf("after page = %+v", p)
+	}
+	if _, err := parsePage(httptest.NewRequest("GET", "/x?before="+c+"&after="+c, nil), 10); err == nil {
+		t.Error("before and after together: want error")
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name string
+		in   string
+		want pageCursor
+		err  bool
+	}{
+		{name: "round trip", in: encodeCursor(pageCursor{Block: 12, Log: 4, ID: 9, Key: "0xabc", Offset: 50}), want: pageCursor{Block: 12, Log: 4, ID: 9, Key: "0xabc", Offset: 50}},
+		{name: "empty object", in: raw(`{}`)},
+		{name: "not base64", in: "***", err: true},
+		{name: "not json", in: raw(`nope`), err: true},
+		{name: "wrong type", in: raw(`{"b":"x"}`), err: true},
+		{name: "negative offset", in: raw(`{"o":-1}`), err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCursor(tt.in)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("want error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyset(t *testing.T) {
+	cols := []string{"block_number", "log_index"}
+	tests := []struct {
+		name               string
+		p                  pageRequest
+		cond, order, limit string
+		args               []any
+	}{
+		{
+			name: "first page",
+			p:    pageRequest{Limit: 10},
+			cond: "", order: "block_number DESC, log_index DESC", limit: "$2",
+			args: []any{"pool", 11},
+		},
+		{
+			name: "before",
+			p:    pageRequest{Limit: 10, Cursor: &pageCursor{Block: 7, Log: 3}},
+			cond: " AND (block_number, log_index) < ($2, $3)", order: "block_number DESC, log_index DESC", limit: "$4",
+			args: []any{"pool", int64(7), int64(3), 11},
+		},
+		{
+			name: "after",
+			p:    pageRequest{Limit: 10, Cursor: &pageCursor{Block: 7, Log: 3}, After: true},
+			cond: " AND (block_number, log_index) > ($2, $3)", order: "block_number ASC, log_index ASC", limit: "$4",
+			args: []any{"pool", int64(7), int64(3), 11},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c pageCursor
+			if tt.p.Cursor != nil {
+				c = *tt.p.Cursor
+			}
+			cond, order, limit, args := tt.p.keyset(cols, []any{"pool"}, c.Block, c.Log)
+			if cond != tt.cond || order != tt.order || limit != tt.limit {
+				t.Errorf("got (%q, %q, %q), want (%q, %q, %q)", cond, order, limit, tt.cond, tt.order, tt.limit)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %v, want %v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestPageOf(t *testing.T) {
+	key := func(n int) pageCursor { return pageCursor{ID: int64(n)} }
+	cursorID := func(t *testing.T, resp pageResponse) int64 {
+		t.Helper()
+		if resp.NextCursor == nil {
+			return -1
+		}
+		c, err := decodeCursor(*resp.NextCursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c.ID
+	}
+	tests := []struct {
+		name   string
+		p      pageRequest
+		rows   []int // as returned by the query, probe row included
+		items  []int
+		cursor int64 // -1 for no next cursor
+	}{
+		// before: rows come newest first; the cursor is the oldest item kept.
+		{"before with probe row", pageRequest{Limit: 3}, []int{9, 8, 7, 6}, []int{9, 8, 7}, 7},
+		{"before last page", pageRequest{Limit: 3}, []int{9, 8}, []int{9, 8}, -1},
+		{"before exact page", pageRequest{Limit: 3}, []int{9, 8, 7}, []int{9, 8, 7}, -1},
+		// after: rows come oldest first and are reversed; the cursor is the newest item kept.
+		{"after with probe row", pageRequest{Limit: 3, After: true}, []int{4, 5, 6, 7}, []int{6, 5, 4}, 6},
+		{"after last page", pageRequest{Limit: 3, After: true}, []int{4, 5}, []int{5, 4}, -1},
+		{"empty", pageRequest{Limit: 3}, nil, nil, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := pageOf(tt.p, append([]int(nil), tt.rows...), key)
+			items := resp.Items.([]int)
+			if len(items) != len(tt.items) || (len(items) > 0 && !reflect.DeepEqual(items, tt.items)) {
+				t.Errorf("items = %v, want %v", items, tt.items)
+			}
+			if got := cursorID(t, resp); got != tt.cursor {
+				t.Errorf("cursor = %d, want %d", got, tt.cursor)
+			}
+		})
+	}
+}
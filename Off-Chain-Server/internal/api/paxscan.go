@@ -70,25 +70,20 @@ func (s *Server) handlePaxscanSync(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Find pool address for this token
-	var poolAddress string
-	err = s.DB.QueryRow(r.Context(), `SELECT pool_address FROM pools WHERE token_address = $1 LIMIT 1`, strings.ToLower(token)).Scan(&poolAddress)
+	var poolAddress, tokenAddress string
+	err = s.DB.QueryRow(r.Context(), `SELECT pool_address, token_address FROM pools WHERE LOWER(token_address) = LOWER($1) LIMIT 1`, token).Scan(&poolAddress, &tokenAddress)
 	if err != nil {
 		// Token not found in pools, still cache the data
 		poolAddress = ""
 	}
 
-	// Upsert token metadata with Paxscan data
+	// Fill in missing token metadata with Paxscan data; fields already set are kept.
 	if poolAddress != "" {
-		_, err = s.DB.Exec(r.Context(), `
-			INSERT INTO pool_metadata(pool_address, token_address, name, symbol, description, logo_url, created_by)
-			VALUES($1, $2, $3, $4, $5, $6, 'paxscan-sync')
-			ON CONFLICT(pool_address) DO UPDATE SET 
-				name = COALESCE(pool_metadata.name, EXCLUDED.name),
-				symbol = COALESCE(pool_metadata.symbol, EXCLUDED.symbol),
-				logo_url = COALESCE(pool_metadata.logo_url, EXCLUDED.logo_url),
-				updated_at = NOW()`,
-			poolAddress, strings.ToLower(token), tokenData.Name, tokenData.Symbol, 
-			fmt.Sprintf("Token with %d holders", holdersCount), tokenData.IconURL)
+		description := fmt.Sprintf("Token with %d holders", holdersCount)
+		_, err = s.writeMetadata(r.Context(), metadataWrite{Entity: poolMetadataEntity, Key: poolAddress, Mode: mergeFill, Source: "paxscan-sync",
+			Values:     map[string]*string{"name": &tokenData.Name, "symbol": &tokenData.Symbol, "description": &description, "logo_url": tokenData.IconURL},
+			Extra:      []colValue{{"token_address", tokenAddress}},
+			InsertOnly: []colValue{{"created_by", "paxscan-sync"}}})
 		if err != nil {
 			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "failed to store metadata", "details": err.Error()})
 			return
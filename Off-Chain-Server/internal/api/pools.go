@@ -0,0 +1,166 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// poolListQuery derives the discovery columns for every pool. Amounts are in
+// USDC wei; volume_24h and the 24h open (the last price at or before 24h ago,
+// or the first price for younger pools) come from the 24h leaderboard_pools
+// rollup the indexer refreshes, and change_24h is the live spot move against
+// that open in percent. Valuations come from the pool_valuations view.
+const poolListQuery = `
+WITH listed AS (
+	SELECT p.pool_address, p.token_address, p.oracle_address, p.created_block, p.created_tx, p.created_time,
+		p.reserve_usdc, p.reserve_token, p.spot_x18, p.floor_x18, p.creator_address, p.progress_pct,
+		m.name, m.symbol, m.logo_url,
+		COALESCE(lp.volume_usdc, 0) AS volume_24h,
+		CASE WHEN lp.open_x18 > 0 THEN ROUND((p.spot_x18 - lp.open_x18) * 100 / lp.open_x18, 4) END AS change_24h,
+		pv.tvl AS liquidity, pv.market_cap, pv.fdv, pv.total_supply, pv.circulating_supply
+	FROM pools p
+	JOIN pool_valuations pv ON pv.pool_address = p.pool_address
+	LEFT JOIN pool_metadata m ON m.pool_address = p.pool_address
+	LEFT JOIN leaderboard_pools lp ON lp.pool_address = p.pool_address AND lp.period = '24h'
+)
+SELECT pool_address, token_address, oracle_address, created_block, created_tx, created_time,
+	reserve_usdc, reserve_token, spot_x18, floor_x18, creator_address, progress_pct, name, symbol, logo_url,
+	volume_24h, change_24h, liquidity, market_cap, fdv, total_supply, circulating_supply
+FROM listed WHERE TRUE`
+
+// poolSorts maps ?sort= to the ordering column; "newest" pages by keyset,
+// the computed ones by offset.
+var poolSorts = map[string]string{
+	"newest":    "",
+	"marketCap": "market_cap",
+	"volume24h": "volume_24h",
+	"change24h": "change_24h",
+	"liquidity": "liquidity",
+	"fdv":       "fdv",
+}
+
+type poolListItem struct {
+	Pool          string     `json:"pool"`
+	Token         string     `json:"token"`
+	Oracle        string     `json:"oracle"`
+	Block         int64      `json:"createdBlock"`
+	Tx            string     `json:"createdTx"`
+	Time          *time.Time `json:"createdTime,omitempty"`
+	ReserveUS     *string    `json:"reserveUSDC,omitempty"`
+	ReserveT      *string    `json:"reserveToken,omitempty"`
+	SpotX18       *string    `json:"spotX18,omitempty"`
+	FloorX18      *string    `json:"floorX18,omitempty"`
+	Creator       *string    `json:"creator,omitempty"`
+	ProgressPct   *string    `json:"progressPct"`
+	Name          *string    `json:"name,omitempty"`
+	Symbol        *string    `json:"symbol,omitempty"`
+	LogoURL       *string    `json:"logoUrl,omitempty"`
+	Volume24hUSDC string     `json:"volume24hUSDC"`
+	Change24hPct  *string    `json:"change24hPct"`
+	LiquidityUSDC string     `json:"liquidityUSDC"`
+	MarketCapUSDC *string    `json:"marketCapUSDC"`
+	FDVUSDC       *string    `json:"fdvUSDC"`
+	TotalSupply   *string    `json:"totalSupply"`
+	Circulating   *string    `json:"circulatingSupply"`
+	Stats         *poolStats `json:"stats,omitempty"`
+}
+
+// likePrefix escapes LIKE wildcards in a user-supplied prefix.
+func likePrefix(q string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return strings.ToLower(r.Replace(q)) + "%"
+}
+
+// GET /pools?q=&sort=&createdAfter=&minLiquidity=&creator=&include=stats&window=&limit=&before=&after=
+// q matches a name, symbol or pool/token address prefix. sort is one of
+// newest (default), marketCap, fdv, volume24h, change24h or liquidity; non-newest
+// sorts are descending and only page forward with "before". include=stats
+// embeds each pool's stats for window (default 24h).
+func (s *Server) handleListPools(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	bad := func(msg string) { writeJSON(w, http.StatusBadRequest, map[string]any{"error": msg}) }
+	p, err := parsePage(r, 100)
+	if err != nil { bad(err.Error()); return }
+	sort := q.Get("sort")
+	if sort == "" { sort = "newest" }
+	sortCol, ok := poolSorts[sort]
+	if !ok { bad("invalid sort"); return }
+	withStats := q.Get("include") == "stats"
+	window := q.Get("window")
+	if window == "" { window = defaultStatsWindow }
+	windowSecs, ok := parseWindow(window)
+	if withStats && (!ok || windowSecs > maxStatsWindow) { bad("invalid window"); return }
+
+	var (
+		sb   strings.Builder
+		args []any
+	)
+	add := func(cond string, v any) {
+		args = append(args, v)
+		fmt.Fprintf(&sb, " AND "+cond, len(args))
+	}
+	if v := strings.TrimSpace(q.Get("q")); v != "" {
+		args = append(args, likePrefix(v))
+		n := len(args)
+		fmt.Fprintf(&sb, " AND (LOWER(name) LIKE $%d OR LOWER(symbol) LIKE $%d OR LOWER(pool_address) LIKE $%d OR LOWER(token_address) LIKE $%d)", n, n, n, n)
+	}
+	if v := q.Get("createdAfter"); v != "" {
+		t, ok := parseTimeParam(v)
+		if !ok { bad("invalid createdAfter"); return }
+		add("created_time >= $%d", t)
+	}
+	if v := q.Get("minLiquidity"); v != "" {
+		n, ok := parseBig(v)
+		if !ok { bad("invalid minLiquidity"); return }
+		add("liquidity >= $%d::numeric", n.String())
+	}
+	if v := q.Get("creator"); v != "" {
+		if !common.IsHexAddress(v) { bad("invalid creator"); return }
+		add("LOWER(creator_address) = LOWER($%d)", v)
+	}
+
+	var c pageCursor
+	if p.Cursor != nil { c = *p.Cursor }
+	query := poolListQuery + sb.String()
+	if sortCol == "" {
+		cond, order, limit, kargs := p.keyset([]string{"created_block", "pool_address"}, args, c.Block, c.Key)
+		query += cond + " ORDER BY " + order + " LIMIT " + limit
+		args = kargs
+	} else {
+		if p.After { bad("after is only supported with sort=newest"); return }
+		args = append(args, p.Limit+1, c.Offset)
+		query += fmt.Sprintf(" ORDER BY %s DESC NULLS LAST, pool_address ASC LIMIT $%d OFFSET $%d", sortCol, len(args)-1, len(args))
+	}
+
+	rows, err := s.DB.Query(r.Context(), query, args...)
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	out := []poolListItem{}
+	for rows.Next() {
+		var it poolListItem
+		if err := rows.Scan(&it.Pool, &it.Token, &it.Oracle, &it.Block, &it.Tx, &it.Time, &it.ReserveUS, &it.ReserveT, &it.SpotX18, &it.FloorX18, &it.Creator, &it.ProgressPct, &it.Name, &it.Symbol, &it.LogoURL, &it.Volume24hUSDC, &it.Change24hPct, &it.LiquidityUSDC, &it.MarketCapUSDC, &it.FDVUSDC, &it.TotalSupply, &it.Circulating); err != nil {
+			writeErr(w, err)
+			return
+		}
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil { writeErr(w, err); return }
+	rows.Close()
+	if withStats {
+		pools := make([]string, len(out))
+		for i := range out { pools[i] = out[i].Pool }
+		stats, err := s.poolStatsBatch(r.Context(), pools, window, windowSecs)
+		if err != nil { writeErr(w, err); return }
+		for i := range out { out[i].Stats = stats[out[i].Pool] }
+	}
+
+	if sortCol == "" {
+		writeJSON(w, http.StatusOK, pageOf(p, out, func(it poolListItem) pageCursor { return pageCursor{Block: it.Block, Key: it.Pool} }))
+		return
+	}
+	writeJSON(w, http.StatusOK, pageOf(p, out, func(poolListItem) pageCursor { return pageCursor{Offset: c.Offset + int64(p.Limit)} }))
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"math/big"
+	"net/http"
+	"time"
+)
+
+// executionX18 is the average price of a swap as an X18 ratio of raw USDC to raw token units.
+func executionX18(usdc, token *big.Int) *string {
+	if token.Sign() == 0 {
+		return nil
+	}
+	p := new(big.Int).Mul(usdc, one18)
+	s := p.Quo(p, token).String()
+	return &s
+}
+
+// GET /accounts/{address}/trades?pool=&limit=&before=&after=
+// Swaps attributed to the address (as transaction sender), newest first.
+func (s *Server) handleAccountTrades(w http.ResponseWriter, r *http.Request) {
+	addr, ok := accountAddress(r.URL.Path, "/trades")
+	if !ok { writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid address"}); return }
+	p, err := parsePage(r, 100)
+	if err != nil { writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()}); return }
+	var c pageCursor
+	if p.Cursor != nil { c = *p.Cursor }
+	cond, order, limit, args := p.keyset([]string{"s.block_number", "s.log_index"}, []any{addr, r.URL.Query().Get("pool")}, c.Block, c.Log)
+	rows, err := s.DB.Query(r.Context(), `
+		SELECT s.pool_address, p.token_address, p.token_decimals, m.name, m.symbol, s.usdc_to_token, s.amount_in::text, s.amount_out::text,
+			s.realized_pnl::text, s.recipient, s.block_number, s.tx_hash, s.log_index, s.block_time
+		FROM swaps s
+		JOIN pools p ON p.pool_address = s.pool_address
+		LEFT JOIN pool_metadata m ON m.pool_address = s.pool_address
+		WHERE s.trader = $1 AND ($2 = '' OR s.pool_address = $2)`+cond+` ORDER BY `+order+` LIMIT `+limit, args...)
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	type row struct {
+		Pool                 string     `json:"pool"`
+		Token                string     `json:"token"`
+		Name                 *string    `json:"name,omitempty"`
+		Symbol               *string    `json:"symbol,omitempty"`
+		Side                 string     `json:"side"`
+		AmountUSDC           string     `json:"amountUSDC"`
+		AmountToken          string     `json:"amountToken"`
+		AmountUSDCFormatted  string     `json:"amountUSDCFormatted"`
+		AmountTokenFormatted string     `json:"amountTokenFormatted"`
+		PriceX18             *string    `json:"priceX18"`
+		Price                *string    `json:"price"`
+		RealizedPnlUSDC      *string    `json:"realizedPnlUSDC"`
+		RealizedPnlFormatted *string    `json:"realizedPnlUSDCFormatted"`
+		Recipient            string     `json:"recipient"`
+		Block                int64      `json:"blockNumber"`
+		Tx                   string     `json:"txHash"`
+		LogIndex             int        `json:"logIndex"`
+		Time                 *time.Time `json:"blockTime,omitempty"`
+	}
+	out := []row{}
+	for rows.Next() {
+		var (
+			rr                  row
+			tokenDec            *int16
+			buy                 bool
+			amountIn, amountOut string
+		)
+		if err := rows.Scan(&rr.Pool, &rr.Token, &tokenDec, &rr.Name, &rr.Symbol, &buy, &amountIn, &amountOut, &rr.RealizedPnlUSDC, &rr.Recipient, &rr.Block, &rr.Tx, &rr.LogIndex, &rr.Time); err != nil { writeErr(w, err); return }
+		dec := poolDecimals{TokenDecimals: defaultTokenDecimals, USDCDecimals: s.usdcDecimals()}
+		if tokenDec != nil { dec.TokenDecimals = int(*tokenDec) }
+		rr.Side, rr.AmountUSDC, rr.AmountToken = "sell", amountOut, amountIn
+		if buy { rr.Side, rr.AmountUSDC, rr.AmountToken = "buy", amountIn, amountOut }
+		rr.AmountUSDCFormatted, rr.AmountTokenFormatted = dec.usdc(rr.AmountUSDC), dec.token(rr.AmountToken)
+		rr.PriceX18 = executionX18(bigOrZero(&rr.AmountUSDC), bigOrZero(&rr.AmountToken))
+		rr.Price = dec.pricePtr(rr.PriceX18)
+		rr.RealizedPnlFormatted = formatUnitsPtr(rr.RealizedPnlUSDC, dec.USDCDecimals)
+		out = append(out, rr)
+	}
+	if err := rows.Err(); err != nil { writeErr(w, err); return }
+	writeJSON(w, http.StatusOK, pageOf(p, out, func(rr row) pageCursor { return eventCursor(rr.Block, rr.LogIndex) }))
+}
+
+type pnlPosition struct {
+	Pool                string    `json:"pool"`
+	Token               string    `json:"token"`
+	Name                *string   `json:"name,omitempty"`
+	Symbol              *string   `json:"symbol,omitempty"`
+	Balance             string    `json:"balance"`
+	BalanceFormatted    string    `json:"balanceFormatted"`
+	CostBasisUSDC       string    `json:"costBasisUSDC"`
+	AvgCostX18          *string   `json:"avgCostX18"`
+	SpotX18             *string   `json:"spotX18"`
+	ValueUSDC           string    `json:"valueUSDC"`
+	RealizedPnlUSDC     string    `json:"realizedPnlUSDC"`
+	UnrealizedPnlUSDC   string    `json:"unrealizedPnlUSDC"`
+	RealizedFormatted   string    `json:"realizedPnlUSDCFormatted"`
+	UnrealizedFormatted string    `json:"unrealizedPnlUSDCFormatted"`
+	SpentUSDC           string    `json:"spentUSDC"`
+	ReceivedUSDC        string    `json:"receivedUSDC"`
+	Trades              int       `json:"trades"`
+	UpdatedAt           time.Time `json:"updatedAt"`
+}
+
+// GET /accounts/{address}/pnl
+// Per-pool average-cost positions built from the address's swaps: realized
+// PnL from sells, and unrealized PnL of the remaining tokens at current spot.
+// Tokens acquired outside the launchpad carry no cost basis and are not counted.
+func (s *Server) handleAccountPnL(w http.ResponseWriter, r *http.Request) {
+	addr, ok := accountAddress(r.URL.Path, "/pnl")
+	if !ok { writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid address"}); return }
+	usdcDec := s.usdcDecimals()
+	rows, err := s.DB.Query(r.Context(), `
+		SELECT a.pool_address, p.token_address, p.token_decimals, m.name, m.symbol, p.spot_x18::text,
+			a.token_balance::text, a.cost_basis_usdc::text, a.realized_pnl_usdc::text, a.spent_usdc::text, a.received_usdc::text, a.trades, a.updated_at
+		FROM account_positions a
+		JOIN pools p ON p.pool_address = a.pool_address
+		LEFT JOIN pool_metadata m ON m.pool_address = a.pool_address
+		WHERE a.trader = $1
+		ORDER BY a.updated_at DESC, a.pool_address`, addr)
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	realized, unrealized, value, cost := new(big.Int), new(big.Int), new(big.Int), new(big.Int)
+	positions := []pnlPosition{}
+	for rows.Next() {
+		var (
+			pp       pnlPosition
+			tokenDec *int16
+		)
+		if err := rows.Scan(&pp.Pool, &pp.Token, &tokenDec, &pp.Name, &pp.Symbol, &pp.SpotX18, &pp.Balance, &pp.CostBasisUSDC, &pp.RealizedPnlUSDC, &pp.SpentUSDC, &pp.ReceivedUSDC, &pp.Trades, &pp.UpdatedAt); err != nil { writeErr(w, err); return }
+		dec := defaultTokenDecimals
+		if tokenDec != nil { dec = int(*tokenDec) }
+		bal, basis := bigOrZero(&pp.Balance), bigOrZero(&pp.CostBasisUSDC)
+		v := valueAtSpot(bal, bigOrZero(pp.SpotX18))
+		u := new(big.Int).Sub(v, basis)
+		pp.BalanceFormatted = formatUnits(pp.Balance, dec)
+		pp.AvgCostX18 = executionX18(basis, bal)
+		pp.ValueUSDC, pp.UnrealizedPnlUSDC = v.String(), u.String()
+		pp.RealizedFormatted, pp.UnrealizedFormatted = formatUnits(pp.RealizedPnlUSDC, usdcDec), formatUnits(pp.UnrealizedPnlUSDC, usdcDec)
+		realized.Add(realized, bigOrZero(&pp.RealizedPnlUSDC))
+		unrealized.Add(unrealized, u)
+		value.Add(value, v)
+		cost.Add(cost, basis)
+		positions = append(positions, pp)
+	}
+	if err := rows.Err(); err != nil { writeErr(w, err); return }
+	total := new(big.Int).Add(realized, unrealized)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"address":   addr,
+		"positions": positions,
+		"totals": map[string]any{
+			"costBasisUSDC":              cost.String(),
+			"valueUSDC":                  value.String(),
+			"realizedPnlUSDC":            realized.String(),
+			"unrealizedPnlUSDC":          unrealized.String(),
+			"totalPnlUSDC":               total.String(),
+			"realizedPnlUSDCFormatted":   formatUnits(realized.String(), usdcDec),
+			"unrealizedPnlUSDCFormatted": formatUnits(unrealized.String(), usdcDec),
+			"totalPnlUSDCFormatted":      formatUnits(total.String(), usdcDec),
+		},
+	})
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// LaunchPool swap fee: 1% = 100 basis points out of 10_000.
+const (
+	poolFeeBps         = 100
+	bpsDenom           = 10_000
+	defaultSlippageBps = 50
+)
+
+var (
+	errNoTokenLiquidity = errors.New("insufficient token liquidity")
+	errNoUSDCLiquidity  = errors.New("no USDC liquidity")
+	errBelowFloor       = errors.New("below floor")
+	errVirtualUnknown   = errors.New("virtual reserves not indexed yet")
+)
+
+var one18 = big.NewInt(1e18)
+
+// poolReserves mirrors the state LaunchPool uses to price swaps.
+type poolReserves struct {
+	VirtualUSDC, VirtualToken *big.Int
+	RealUSDC, RealToken       *big.Int
+	FloorX18                  *big.Int
+}
+
+type swapQuote struct {
+	AmountOut     *big.Int
+	Fee           *big.Int // always denominated in USDC
+	PriceAfterX18 *big.Int
+}
+
+func (p poolReserves) spotX18() *big.Int {
+	return priceX18(new(big.Int).Add(p.VirtualUSDC, p.RealUSDC), new(big.Int).Add(p.VirtualToken, p.RealToken))
+}
+
+func (p poolReserves) k() *big.Int {
+	return new(big.Int).Mul(new(big.Int).Add(p.VirtualUSDC, p.RealUSDC), new(big.Int).Add(p.VirtualToken, p.RealToken))
+}
+
+// quoteBuy reproduces LaunchPool.swapExactUSDCForTokens: the 1% fee is taken
+// from the USDC input before the constant-product step.
+func (p poolReserves) quoteBuy(amountIn *big.Int) (swapQuote, error) {
+	fee := new(big.Int).Quo(new(big.Int).Mul(amountIn, big.NewInt(poolFeeBps)), big.NewInt(bpsDenom))
+	newRealUSDC := new(big.Int).Add(p.RealUSDC, new(big.Int).Sub(amountIn, fee))
+	newTokenPlusVirtual := new(big.Int).Quo(p.k(), new(big.Int).Add(p.VirtualUSDC, newRealUSDC))
+	newRealToken := new(big.Int).Sub(newTokenPlusVirtual, p.VirtualToken)
+	if newRealToken.Sign() < 0 {
+		return swapQuote{}, errNoTokenLiquidity
+	}
+	return swapQuote{
+		AmountOut:     new(big.Int).Sub(p.RealToken, newRealToken),
+		Fee:           fee,
+		PriceAfterX18: priceX18(new(big.Int).Add(p.VirtualUSDC, newRealUSDC), newTokenPlusVirtual),
+	}, nil
+}
+
+// quoteSell reproduces LaunchPool.swapExactTokensForUSDC: the 1% fee is taken
+// from the USDC output and the trade reverts if it ends below the floor.
+func (p poolReserves) quoteSell(amountIn *big.Int) (swapQuote, error) {
+	newRealToken := new(big.Int).Add(p.RealToken, amountIn)
+	newTokenPlusVirtual := new(big.Int).Add(p.VirtualToken, newRealToken)
+	newUSDCPlusVirtual := new(big.Int).Quo(p.k(), newTokenPlusVirtual)
+	if newUSDCPlusVirtual.Cmp(p.VirtualUSDC) <= 0 {
+		return swapQuote{}, errNoUSDCLiquidity
+	}
+	newRealUSDC := new(big.Int).Sub(newUSDCPlusVirtual, p.VirtualUSDC)
+	gross := new(big.Int).Sub(p.RealUSDC, newRealUSDC)
+	if gross.Sign() <= 0 {
+		return swapQuote{}, errNoUSDCLiquidity
+	}
+	fee := new(big.Int).Quo(new(big.Int).Mul(gross, big.NewInt(poolFeeBps)), big.NewInt(bpsDenom))
+	after := priceX18(newUSDCPlusVirtual, newTokenPlusVirtual)
+	if p.FloorX18 != nil && after.Cmp(p.FloorX18) < 0 {
+		return swapQuote{}, errBelowFloor
+	}
+	return swapQuote{AmountOut: gross.Sub(gross, fee), Fee: fee, PriceAfterX18: after}, nil
+}
+
+func priceX18(usdc, token *big.Int) *big.Int {
+	if token.Sign() == 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Quo(new(big.Int).Mul(usdc, one18), token)
+}
+
+// priceImpactBps is |after - before| / before in basis points, rounded down.
+func priceImpactBps(before, after *big.Int) *big.Int {
+	impact := new(big.Int).Sub(after, before)
+	impact.Abs(impact).Mul(impact, big.NewInt(bpsDenom))
+	if before.Sign() > 0 {
+		impact.Quo(impact, before)
+	}
+	return impact
+}
+
+// applySlippage returns amount * (10_000 - bps) / 10_000.
+func applySlippage(amount *big.Int, bps int64) *big.Int {
+	out := new(big.Int).Mul(amount, big.NewInt(bpsDenom-bps))
+	return out.Quo(out, big.NewInt(bpsDenom))
+}
+
+func parseBig(s string) (*big.Int, bool) {
+	if s == "" {
+		return nil, false
+	}
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok || v.Sign() < 0 {
+		return nil, false
+	}
+	return v, true
+}
+
+// loadPoolReserves reads the indexed reserve snapshot and virtual reserves of a pool.
+func (s *Server) loadPoolReserves(ctx context.Context, pool string) (*poolReserves, error) {
+	var vUSDC, vToken, rUSDC, rToken, floor *string
+	err := s.DB.QueryRow(ctx, `SELECT virtual_usdc, virtual_token, reserve_usdc, reserve_token, floor_x18 FROM pools WHERE pool_address = $1`, pool).
+		Scan(&vUSDC, &vToken, &rUSDC, &rToken, &floor)
+	if err != nil {
+		return nil, err
+	}
+	if vUSDC == nil || vToken == nil {
+		return nil, errVirtualUnknown
+	}
+	p := &poolReserves{RealUSDC: new(big.Int), RealToken: new(big.Int)}
+	p.VirtualUSDC, _ = parseBig(*vUSDC)
+	p.VirtualToken, _ = parseBig(*vToken)
+	if rUSDC != nil {
+		if v, ok := parseBig(*rUSDC); ok {
+			p.RealUSDC = v
+		}
+	}
+	if rToken != nil {
+		if v, ok := parseBig(*rToken); ok {
+			p.RealToken = v
+		}
+	}
+	if floor != nil {
+		p.FloorX18, _ = parseBig(*floor)
+	}
+	if p.VirtualUSDC == nil || p.VirtualToken == nil {
+		return nil, errVirtualUnknown
+	}
+	return p, nil
+}
+
+// GET /pools/{pool}/quote?side=buy|sell&amountIn=&slippageBps=
+// Quotes a swap off-chain from the indexed reserves, including the 1% fee.
+func (s *Server) handleQuote(w http.ResponseWriter, r *http.Request) {
+	pool := extractBetween(r.URL.Path, "/pools/", "/quote")
+	q := r.URL.Query()
+	side := q.Get("side")
+	if side != "buy" && side != "sell" {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "side must be buy or sell"})
+		return
+	}
+	amountIn, ok := parseBig(q.Get("amountIn"))
+	if !ok || amountIn.Sign() == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid amountIn"})
+		return
+	}
+	slippage := int64(defaultSlippageBps)
+	if v := q.Get("slippageBps"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 || n > bpsDenom {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid slippageBps"})
+			return
+		}
+		slippage = n
+	}
+
+	res, err := s.loadPoolReserves(r.Context(), pool)
+	if err != nil {
+		switch {
+		case errors.Is(err, errVirtualUnknown):
+			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
+		case errors.Is(err, pgx.ErrNoRows):
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "pool not found"})
+		default:
+			writeErr(w, err)
+		}
+		return
+	}
+	var quote swapQuote
+	if side == "buy" {
+		quote, err = res.quoteBuy(amountIn)
+	} else {
+		quote, err = res.quoteSell(amountIn)
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+
+	before := res.spotX18()
+	impact := priceImpactBps(before, quote.PriceAfterX18)
+	exec := new(big.Int)
+	if quote.AmountOut.Sign() > 0 {
+		if side == "buy" {
+			exec = priceX18(amountIn, quote.AmountOut)
+		} else {
+			exec = priceX18(quote.AmountOut, amountIn)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"pool":              pool,
+		"side":              side,
+		"amountIn":          amountIn.String(),
+		"amountOut":         quote.AmountOut.String(),
+		"fee":               quote.Fee.String(),
+		"priceBeforeX18":    before.String(),
+		"priceAfterX18":     quote.PriceAfterX18.String(),
+		"executionPriceX18": exec.String(),
+		"priceImpactBps":    impact.String(),
+		"slippageBps":       slippage,
+		"minOut":            applySlippage(quote.AmountOut, slippage).String(),
+	})
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func bigStr(t *testing.T, s string) *big.Int {
+	t.Helper()
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("bad number %q", s)
+	}
+	return v
+}
+
+// Expected values follow LaunchPool: a buy is quoteUSDCToToken(amountIn - fee)
+// and a sell is quoteTokenToUSDC(amountIn) minus the 1% fee, with the
+// contract's integer division at every step.
+func TestQuoteMatchesLaunchPool(t *testing.T) {
+	const e18 = "000000000000000000"
+	tests := []struct {
+		name                       string
+		vUSDC, vToken, rUSDC, rTok string
+		floor                      string
+		side                       string
+		amountIn                   string
+		amountOut, fee             string
+		before, after              string
+		impactBps                  int64
+		minOut50                   string
+		err                        error
+	}{
+		{
+			name: "fresh pool buy", vUSDC: "10000" + e18, vToken: "0", rUSDC: "0", rTok: "1000000000" + e18,
+			side: "buy", amountIn: "100" + e18,
+			amountOut: "9802950787206654124170710", fee: "1" + e18,
+			before: "10000000000000", after: "10198980100000", impactBps: 198,
+			minOut50: "9753936033270620853549856",
+		},
+		{
+			// The fee rounds down to 1 wei and the price does not move.
+			name: "dust buy", vUSDC: "10000" + e18, vToken: "0", rUSDC: "0", rTok: "1000000000" + e18,
+			side: "buy", amountIn: "199",
+			amountOut: "19800000", fee: "1",
+			before: "10000000000000", after: "10000000000000", impactBps: 0,
+			minOut50: "19701000",
+		},
+		{
+			name: "buy with real USDC", vUSDC: "10000" + e18, vToken: "0", rUSDC: "2500" + e18, rTok: "800000000" + e18,
+			side: "buy", amountIn: "1234567890123456789",
+			amountOut: "78214573871068102151909", fee: "12345678901234567",
+			before: "15625000000000", after: "15628055704910", impactBps: 1,
+			minOut50: "77823501001712761641149",
+		},
+		{
+			name: "sell", vUSDC: "10000" + e18, vToken: "0", rUSDC: "2500" + e18, rTok: "800000000" + e18,
+			side: "sell", amountIn: "5000000" + e18,
+			amountOut: "76863354037267080746", fee: "776397515527950310",
+			before: "15625000000000", after: "15431503414220", impactBps: 123,
+			minOut50: "76479037267080745342",
+		},
+		{
+			name: "large odd sell", vUSDC: "10000" + e18, vToken: "0", rUSDC: "2500" + e18, rTok: "800000000" + e18,
+			side: "sell", amountIn: "123456789000000000000000007",
+			amountOut: "1654411751663455473282", fee: "16711229814782378517",
+			before: "15625000000000", after: "11726457748226", impactBps: 2495,
+			minOut50: "1646139692905138195915",
+		},
+		{
+			name: "buy with virtual token", vUSDC: "5000" + e18, vToken: "200000000" + e18, rUSDC: "1000" + e18, rTok: "700000000" + e18,
+			side: "buy", amountIn: "50" + e18,
+			amountOut: "7364244978923878006446814", fee: "500000000000000000",
+			before: "6666666666666", after: "6777120416666", impactBps: 165,
+			minOut50: "7327423754029258616414579",
+		},
+		{
+			name: "sell with virtual token", vUSDC: "5000" + e18, vToken: "200000000" + e18, rUSDC: "1000" + e18, rTok: "700000000" + e18,
+			side: "sell", amountIn: "10000000" + e18,
+			amountOut: "65274725274725274726", fee: "659340659340659340",
+			before: "6666666666666", after: "6520951575896", impactBps: 218,
+			minOut50: "64948351648351648352",
+		},
+		{
+			name: "sell above floor", vUSDC: "10000" + e18, vToken: "0", rUSDC: "2500" + e18, rTok: "800000000" + e18,
+			floor: "15000000000000", side: "sell", amountIn: "5000000" + e18,
+			amountOut: "76863354037267080746", fee: "776397515527950310",
+			before: "15625000000000", after: "15431503414220", impactBps: 123,
+			minOut50: "76479037267080745342",
+		},
+		{
+			name: "sell below floor", vUSDC: "10000" + e18, vToken: "0", rUSDC: "2500" + e18, rTok: "800000000" + e18,
+			floor: "15000000000000", side: "sell", amountIn: "123456789000000000000000007",
+			err: errBelowFloor,
+		},
+		{
+			// quoteTokenToUSDC returns 0 without real USDC; the swap would revert.
+			name: "sell without real USDC", vUSDC: "10000" + e18, vToken: "0", rUSDC: "0", rTok: "1000000000" + e18,
+			side: "sell", amountIn: "1" + e18,
+			err: errNoUSDCLiquidity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := poolReserves{
+				VirtualUSDC: bigStr(t, tt.vUSDC), VirtualToken: bigStr(t, tt.vToken),
+				RealUSDC: bigStr(t, tt.rUSDC), RealToken: bigStr(t, tt.rTok),
+			}
+			if tt.floor != "" {
+				p.FloorX18 = bigStr(t, tt.floor)
+			}
+			var (
+				q   swapQuote
+				err error
+			)
+			if tt.side == "buy" {
+				q, err = p.quoteBuy(bigStr(t, tt.amountIn))
+			} else {
+				q, err = p.quoteSell(bigStr(t, tt.amountIn))
+			}
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			before := p.spotX18()
+			checks := []struct {
+				field     string
+				got, want *big.Int
+			}{
+				{"amountOut", q.AmountOut, bigStr(t, tt.amountOut)},
+				{"fee", q.Fee, bigStr(t, tt.fee)},
+				{"priceBefore", before, bigStr(t, tt.before)},
+				{"priceAfter", q.PriceAfterX18, bigStr(t, tt.after)},
+				{"impactBps", priceImpactBps(before, q.PriceAfterX18), big.NewInt(tt.impactBps)},
+				{"minOut", applySlippage(q.AmountOut, defaultSlippageBps), bigStr(t, tt.minOut50)},
+			}
+			for _, c := range checks {
+				if c.got.Cmp(c.want) != 0 {
+					t.Errorf("%s = %s, want %s", c.field, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPriceImpactBpsRoundsDown(t *testing.T) {
+	tests := []struct {
+		before, after, want int64
+	}{
+		{3, 4, 3333},
+		{10000, 10001, 1},
+		{10000, 10000, 0},
+		{10000, 9999, 1},
+		{3, 2, 3333},
+	}
+	for _, tt := range tests {
+		if got := priceImpactBps(big.NewInt(tt.before), big.NewInt(tt.after)); got.Int64() != tt.want {
+			t.Errorf("priceImpactBps(%d, %d) = %s, want %d", tt.before, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestApplySlippageRoundsDown(t *testing.T) {
+	tests := []struct {
+		amount, bps, want int64
+	}{
+		{19800000, 50, 19701000},
+		{199, 1, 198},
+		{199, 0, 199},
+		{199, 10000, 0},
+	}
+	for _, tt := range tests {
+		if got := applySlippage(big.NewInt(tt.amount), tt.bps); got.Int64() != tt.want {
+			t.Errorf("applySlippage(%d, %d) = %s, want %d", tt.amount, tt.bps, got, tt.want)
+		}
+	}
+}
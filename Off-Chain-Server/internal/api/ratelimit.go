@@ -0,0 +1,279 @@
+package api
+
+import (
+	"context"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/paxeer/offchain-server/internal/config"
+)
+
+// parseTrustedProxies parses api.trustedProxies; a bare IP is a single-host network.
+func parseTrustedProxies(entries []string) []*net.IPNet {
+	var out []*net.IPNet
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		if !strings.Contains(e, "/") {
+			if ip := net.ParseIP(e); ip != nil {
+				bits := 128
+				if ip.To4() != nil {
+					bits = 32
+				}
+				e += "/" + strconv.Itoa(bits)
+			}
+		}
+		_, n, err := net.ParseCIDR(e)
+		if err != nil {
+			log.Printf("[warn] ignoring trusted proxy %q: %v", e, err)
+			continue
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+func (s *Server) trustedProxy(ip net.IP) bool {
+	for _, n := range s.trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP is the address the request came from. X-Forwarded-For is only
+// believed when the connection comes from a trusted proxy; it is read right
+// to left, skipping further trusted proxies.
+func (s *Server) clientIP(r *http.Request) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+	if ip := net.ParseIP(remote); ip == nil || !s.trustedProxy(ip) {
+		return remote
+	}
+	var hops []string
+	for _, h := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(h, ",")...)
+	}
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		client = ip.String()
+		if !s.trustedProxy(ip) {
+			break
+		}
+	}
+	return client
+}
+
+// requestAccount is the signed-in address behind a session or API key, or "".
+func (s *Server) requestAccount(r *http.Request) string {
+	if addr, ok := s.getSessionAddress(r); ok {
+		return strings.ToLower(addr)
+	}
+	if key := bearerToken(r); strings.HasPrefix(key, apiKeyPrefix) {
+		if addr, _, ok := s.apiKeyAddress(r, key); ok {
+			return strings.ToLower(addr)
+		}
+	}
+	return ""
+}
+
+// rateStore keeps token buckets.
+type rateStore interface {
+	// take removes a token from the bucket at key, which refills at rate
+	// tokens per second up to burst, and reports whether one was available
+	// and how many are left.
+	take(ctx context.Context, key string, rate float64, burst int) (bool, float64, error)
+	// prune drops buckets that have refilled completely.
+	prune(ctx context.Context) error
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+// memoryStore keeps buckets in this process only.
+type memoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+func newMemoryStore() *memoryStore { return &memoryStore{buckets: map[string]*bucket{}} }
+
+func (m *memoryStore) take(_ context.Context, key string, rate float64, burst int) (bool, float64, error) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), updated: now}
+		m.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	b.full = now.Add(time.Duration((float64(burst) - b.tokens) / rate * float64(time.Second)))
+	return allowed, b.tokens, nil
+}
+
+func (m *memoryStore) prune(context.Context) error {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for k, b := range m.buckets {
+		if b.full.Before(now) {
+			delete(m.buckets, k)
+		}
+	}
+	return nil
+}
+
+// pgStore keeps buckets in rate_limit_buckets so all API instances share them.
+type pgStore struct{ db *pgxpool.Pool }
+
+func (p pgStore) take(ctx context.Context, key string, rate float64, burst int) (bool, float64, error) {
+	var (
+		allowed   bool
+		remaining float64
+	)
+	err := p.db.QueryRow(ctx, `SELECT allowed, remaining FROM rate_limit_take($1, $2, $3)`, key, rate, float64(burst)).Scan(&allowed, &remaining)
+	return allowed, remaining, err
+}
+
+func (p pgStore) prune(ctx context.Context) error {
+	_, err := p.db.Exec(ctx, `DELETE FROM rate_limit_buckets WHERE full_at < NOW()`)
+	return err
+}
+
+type ratePolicy struct {
+	config.RateLimitPolicy
+	perSecond float64
+}
+
+func (p ratePolicy) matches(r *http.Request) bool {
+	if p.Method != "" && !strings.EqualFold(p.Method, r.Method) {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(p.Path, "*"); ok {
+		return strings.HasPrefix(r.URL.Path, prefix)
+	}
+	return p.Path == "" || p.Path == r.URL.Path
+}
+
+// RateLimiter applies the first matching policy's token buckets before
+// handing the request to the server.
+type RateLimiter struct {
+	s        *Server
+	store    rateStore
+	policies []ratePolicy
+}
+
+// WithRateLimits wraps the server in the configured rate limiter and prunes
+// idle buckets until ctx is done. With the "off" backend it returns s itself.
+func (s *Server) WithRateLimits(ctx context.Context) http.Handler {
+	if s.Config == nil || s.Config.API.RateLimit.Backend == config.RateLimitOff {
+		return s
+	}
+	rl := &RateLimiter{s: s, store: newMemoryStore()}
+	if s.Config.API.RateLimit.Backend == config.RateLimitPostgres {
+		rl.store = pgStore{db: s.DB}
+	}
+	for _, p := range s.Config.API.RateLimit.Policies {
+		perSecond, err := p.PerSecond()
+		if err != nil {
+			log.Printf("[warn] skipping %v", err)
+			continue
+		}
+		rl.policies = append(rl.policies, ratePolicy{RateLimitPolicy: p, perSecond: perSecond})
+	}
+	go func() {
+		t := time.NewTicker(time.Minute)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if err := rl.store.prune(ctx); err != nil && ctx.Err() == nil {
+					log.Printf("[warn] prune rate limit buckets: %v", err)
+				}
+			}
+		}
+	}()
+	return rl
+}
+
+func (rl *RateLimiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		rl.s.ServeHTTP(w, r)
+		return
+	}
+	var p *ratePolicy
+	for i := range rl.policies {
+		if rl.policies[i].matches(r) {
+			p = &rl.policies[i]
+			break
+		}
+	}
+	if p == nil {
+		rl.s.ServeHTTP(w, r)
+		return
+	}
+
+	var keys []string
+	for _, by := range p.By {
+		switch by {
+		case "ip":
+			keys = append(keys, p.Name+"|ip:"+rl.s.clientIP(r))
+		case "account":
+			if addr := rl.s.requestAccount(r); addr != "" {
+				keys = append(keys, p.Name+"|account:"+addr)
+			}
+		}
+	}
+	allowed, remaining := true, float64(p.Burst)
+	for _, key := range keys {
+		ok, left, err := rl.store.take(r.Context(), key, p.perSecond, p.Burst)
+		if err != nil {
+			// Fail open: a store outage should not take the API down.
+			log.Printf("[warn] rate limit %s: %v", p.Name, err)
+			continue
+		}
+		allowed = allowed && ok
+		remaining = math.Min(remaining, left)
+	}
+
+	h := w.Header()
+	h.Set("X-RateLimit-Limit", strconv.Itoa(p.Burst))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(int(math.Floor(remaining))))
+	h.Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil((float64(p.Burst)-remaining)/p.perSecond))))
+	if allowed {
+		rl.s.ServeHTTP(w, r)
+		return
+	}
+	retry := int(math.Max(1, math.Ceil((1-remaining)/p.perSecond)))
+	h.Set("Retry-After", strconv.Itoa(retry))
+	rl.s.setCORSHeaders(w, r)
+	h.Set("Content-Type", "application/json")
+	writeJSON(w, http.StatusTooManyRequests, map[string]any{"error": "rate limit exceeded", "retryAfter": retry})
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/paxeer/offchain-server/internal/config"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	nets := parseTrustedProxies([]string{"10.0.0.0/8", " 192.168.1.5 ", "::1", "", "nope"})
+	want := []string{"10.0.0.0/8", "192.168.1.5/32", "::1/128"}
+	if len(nets) != len(want) {
+		t.Fatalf("got %v, want %v", nets, want)
+	}
+	for i, n := range nets {
+		if n.String() != want[i] {
+			t.Errorf("net %d = %s, want %s", i, n, want[i])
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	s := &Server{trustedProxies: parseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.5"})}
+	tests := []struct {
+		name   string
+		remote string
+		xff    []string
+		want   string
+	}{
+		{name: "no header", remote: "203.0.113.7:5000", want: "203.0.113.7"},
+		{name: "spoofed header from untrusted peer", remote: "203.0.113.7:5000", xff: []string{"1.2.3.4"}, want: "203.0.113.7"},
+		{name: "trusted proxy", remote: "10.0.0.2:5000", xff: []string{"198.51.100.9"}, want: "198.51.100.9"},
+		{name: "trusted proxy without header", remote: "10.0.0.2:5000", want: "10.0.0.2"},
+		{name: "chain of trusted proxies", remote: "10.0.0.2:5000", xff: []string{"198.51.100.9, 192.168.1.5, 10.1.2.3"}, want: "198.51.100.9"},
+		{name: "client-supplied hops left of the client are ignored", remote: "10.0.0.2:5000", xff: []string{"1.2.3.4, 198.51.100.9", "10.1.2.3"}, want: "198.51.100.9"},
+		{name: "all hops trusted", remote: "10.0.0.2:5000", xff: []string{"10.9.9.9, 192.168.1.5"}, want: "10.9.9.9"},
+		{name: "garbage hop stops the walk", remote: "10.0.0.2:5000", xff: []string{"198.51.100.9, junk, 10.1.2.3"}, want: "10.1.2.3"},
+		{name: "ipv6 peer", remote: "[2001:db8::1]:5000", xff: []string{"1.2.3.4"}, want: "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/x", nil)
+			r.RemoteAddr = tt.remote
+			for _, h := range tt.xff {
+				r.Header.Add("X-Forwarded-For", h)
+			}
+			if got := s.clientIP(r); got != tt.want {
+				t.Errorf("clientIP = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	ctx := context.Background()
+	m := newMemoryStore()
+	const rate, burst = 2.0, 3
+	for i := 0; i < burst; i++ {
+		ok, left, _ := m.take(ctx, "k", rate, burst)
+		if !ok || math.Abs(left-float64(burst-1-i)) > 0.01 {
+			t.Fatalf("take %d = %v, %.2f left, want true, %d left", i, ok, left, burst-1-i)
+		}
+	}
+	if ok, _, _ := m.take(ctx, "k", rate, burst); ok {
+		t.Fatal("take from an empty bucket allowed")
+	}
+	if ok, _, _ := m.take(ctx, "other", rate, burst); !ok {
+		t.Fatal("buckets are not independent")
+	}
+
+	// One second at 2/s refills two tokens.
+	m.buckets["k"].updated = m.buckets["k"].updated.Add(-time.Second)
+	ok, left, _ := m.take(ctx, "k", rate, burst)
+	if !ok || math.Abs(left-1) > 0.01 {
+		t.Errorf("after refill = %v, %.2f left, want true, 1 left", ok, left)
+	}
+	// Refill stops at burst.
+	m.buckets["k"].updated = m.buckets["k"].updated.Add(-time.Hour)
+	if _, left, _ = m.take(ctx, "k", rate, burst); math.Abs(left-float64(burst-1)) > 0.01 {
+		t.Errorf("after long idle %.2f left, want %d", left, burst-1)
+	}
+
+	m.buckets["k"].full = time.Now().Add(-time.Second)
+	if err := m.prune(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.buckets["k"]; ok {
+		t.Error("full bucket was not pruned")
+	}
+	if _, ok := m.buckets["other"]; !ok {
+		t.Error("refilling bucket was pruned")
+	}
+}
+
+func TestRateLimiterRetryAfter(t *testing.T) {
+	s := &Server{}
+	p := ratePolicy{RateLimitPolicy: config.RateLimitPolicy{Name: "writes", Method: "POST", Burst: 2, By: []string{"ip"}}, perSecond: 0.25}
+	rl := &RateLimiter{s: s, store: newMemoryStore(), policies: []ratePolicy{p}}
+	for i := 0; i < p.Burst; i++ {
+		if _, _, err := rl.store.take(context.Background(), "writes|ip:203.0.113.7", p.perSecond, p.Burst); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := httptest.NewRequest("POST", "/x", nil)
+	r.RemoteAddr = "203.0.113.7:5000"
+	w := httptest.NewRecorder()
+	rl.ServeHTTP(w, r)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	// An empty bucket needs one token at 0.25/s: 4 seconds.
+	for h, want := range map[string]string{"Retry-After": "4", "X-RateLimit-Limit": "2", "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "8"} {
+		if got := w.Header().Get(h); got != want {
+			t.Errorf("%s = %q, want %q", h, got, want)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// metadataEntity is a table whose user-facing fields are versioned in
+// metadata_revisions. Column names are fixed here and never come from input.
+type metadataEntity struct {
+	kind   string
+	table  string
+	key    string
+	fields []string
+}
+
+var (
+	poolMetadataEntity = metadataEntity{kind: "pool", table: "pool_metadata", key: "pool_address",
+		fields: []string{"name", "symbol", "description", "website_url", "twitter_url", "telegram_url", "logo_url", "banner_url"}}
+	profileEntity = metadataEntity{kind: "profile", table: "profiles", key: "address",
+		fields: []string{"username", "bio", "avatar_url", "banner_url", "website_url", "twitter_url", "telegram_url"}}
+)
+
+// writeMode selects how supplied values combine with the stored row.
+type writeMode int
+
+const (
+	// mergeSet overwrites fields that are supplied (non-nil).
+	mergeSet writeMode = iota
+	// mergeFill only fills fields that are currently empty.
+	mergeFill
+	// createOnly writes only when the row does not exist yet.
+	createOnly
+	// replaceAll sets every field, clearing those not supplied (reverts).
+	replaceAll
+)
+
+type colValue struct {
+	Col string
+	Val any
+}
+
+// metadataWrite is one change to a versioned row.
+type metadataWrite struct {
+	Entity metadataEntity
+	Key    string
+	Values map[string]*string
+	// Extra columns written on insert and update, e.g. updated_by.
+	Extra []colValue
+	// InsertOnly columns written only when the row is created, e.g. created_by.
+	InsertOnly []colValue
+	Mode       writeMode
+	Editor     string
+	Source     string
+	// RevertedFrom is the revision a revert restored.
+	RevertedFrom *int
+}
+
+type fieldDiff struct {
+	From *string `json:"from"`
+	To   *string `json:"to"`
+}
+
+func sameValue(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func isEmpty(v *string) bool { return v == nil || *v == "" }
+
+// writeMetadata applies a change and records it as a revision in the same
+// transaction. It returns the new revision number, or 0 when nothing changed.
+func (s *Server) writeMetadata(ctx context.Context, mw metadataWrite) (int, error) {
+	e := mw.Entity
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	before := make([]*string, len(e.fields))
+	dest := make([]any, len(e.fields))
+	for i := range before {
+		dest[i] = &before[i]
+	}
+	exists := true
+	err = tx.QueryRow(ctx, `SELECT `+strings.Join(e.fields, ", ")+` FROM `+e.table+` WHERE `+e.key+` = $1 FOR UPDATE`, mw.Key).Scan(dest...)
+	if err == pgx.ErrNoRows {
+		exists = false
+	} else if err != nil {
+		return 0, err
+	}
+	if exists && mw.Mode == createOnly {
+		return 0, nil
+	}
+
+	snapshot := make(map[string]*string, len(e.fields))
+	diff := map[string]fieldDiff{}
+	for i, f := range e.fields {
+		after := before[i]
+		v, ok := mw.Values[f]
+		switch mw.Mode {
+		case replaceAll:
+			after = v
+		case mergeFill:
+			if ok && v != nil && isEmpty(before[i]) { after = v }
+		default:
+			if ok && v != nil { after = v }
+		}
+		snapshot[f] = after
+		if !sameValue(before[i], after) {
+			diff[f] = fieldDiff{From: before[i], To: after}
+		}
+	}
+	if exists && len(diff) == 0 {
+		return 0, nil
+	}
+
+	if !exists {
+		cols := append([]colValue{{e.key, mw.Key}}, mw.Extra...)
+		cols = append(cols, mw.InsertOnly...)
+		for _, f := range e.fields {
+			cols = append(cols, colValue{f, snapshot[f]})
+		}
+		names, marks, args := make([]string, len(cols)), make([]string, len(cols)), make([]any, len(cols))
+		for i, c := range cols {
+			names[i], marks[i], args[i] = c.Col, fmt.Sprintf("$%d", i+1), c.Val
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO `+e.table+`(`+strings.Join(names, ", ")+`) VALUES(`+strings.Join(marks, ",")+`)`, args...)
+	} else {
+		sets, args := []string{}, []any{mw.Key}
+		set := func(col string, v any) {
+			args = append(args, v)
+			sets = append(sets, fmt.Sprintf("%s = $%d", col, len(args)))
+		}
+		for _, c := range mw.Extra {
+			set(c.Col, c.Val)
+		}
+		for _, f := range e.fields {
+			if _, changed := diff[f]; changed { set(f, snapshot[f]) }
+		}
+		_, err = tx.Exec(ctx, `UPDATE `+e.table+` SET `+strings.Join(sets, ", ")+`, updated_at = NOW() WHERE `+e.key+` = $1`, args...)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	snapJSON, err := json.Marshal(snapshot)
+	if err != nil {
+		return 0, err
+	}
+	diffJSON, err := json.Marshal(diff)
+	if err != nil {
+		return 0, err
+	}
+	var rev int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO metadata_revisions(entity, entity_key, revision, editor, source, snapshot, diff, reverted_from)
+		SELECT $1, $2, COALESCE(MAX(revision), 0) + 1, NULLIF($3, ''), $4, $5, $6, $7
+		FROM metadata_revisions WHERE entity = $1 AND entity_key = $2
+		RETURNING revision`, e.kind, mw.Key, mw.Editor, mw.Source, snapJSON, diffJSON, mw.RevertedFrom).Scan(&rev)
+	if err != nil {
+		return 0, err
+	}
+	return rev, tx.Commit(ctx)
+}
+
+type metadataRevision struct {
+	Revision     int                  `json:"revision"`
+	Editor       *string              `json:"editor"`
+	Source       string               `json:"source"`
+	Snapshot     map[string]*string   `json:"snapshot"`
+	Diff         map[string]fieldDiff `json:"diff"`
+	RevertedFrom *int                 `json:"revertedFrom,omitempty"`
+	CreatedAt    time.Time            `json:"createdAt"`
+}
+
+// loadRevision returns one stored revision, or pgx.ErrNoRows.
+func (s *Server) loadRevision(ctx context.Context, e metadataEntity, key string, revision int) (*metadataRevision, error) {
+	var (
+		rv         metadataRevision
+		snap, diff []byte
+	)
+	err := s.DB.QueryRow(ctx, `SELECT revision, editor, source, snapshot, diff, reverted_from, created_at FROM metadata_revisions WHERE entity = $1 AND entity_key = $2 AND revision = $3`, e.kind, key, revision).
+		Scan(&rv.Revision, &rv.Editor, &rv.Source, &snap, &diff, &rv.RevertedFrom, &rv.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(snap, &rv.Snapshot); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(diff, &rv.Diff); err != nil {
+		return nil, err
+	}
+	return &rv, nil
+}
+
+// writeHistory pages through an entity's revisions, newest first.
+func (s *Server) writeHistory(w http.ResponseWriter, r *http.Request, e metadataEntity, key string) {
+	p, err := parsePage(r, 50)
+	if err != nil { writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()}); return }
+	var c pageCursor
+	if p.Cursor != nil { c = *p.Cursor }
+	cond, order, limit, args := p.keyset([]string{"revision"}, []any{e.kind, key}, c.ID)
+	rows, err := s.DB.Query(r.Context(), `SELECT revision, editor, source, snapshot, diff, reverted_from, created_at FROM metadata_revisions WHERE entity = $1 AND entity_key = $2`+cond+` ORDER BY `+order+` LIMIT `+limit, args...)
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	out := []metadataRevision{}
+	for rows.Next() {
+		var (
+			rv         metadataRevision
+			snap, diff []byte
+		)
+		if err := rows.Scan(&rv.Revision, &rv.Editor, &rv.Source, &snap, &diff, &rv.RevertedFrom, &rv.CreatedAt); err != nil { writeErr(w, err); return }
+		if err := json.Unmarshal(snap, &rv.Snapshot); err != nil { writeErr(w, err); return }
+		if err := json.Unmarshal(diff, &rv.Diff); err != nil { writeErr(w, err); return }
+		out = append(out, rv)
+	}
+	if err := rows.Err(); err != nil { writeErr(w, err); return }
+	writeJSON(w, http.StatusOK, pageOf(p, out, func(rv metadataRevision) pageCursor { return pageCursor{ID: int64(rv.Revision)} }))
+}
+
+// revertTo restores the fields of an earlier revision as a new revision.
+func (s *Server) revertTo(w http.ResponseWriter, r *http.Request, mw metadataWrite) {
+	var in struct{ Revision int `json:"revision"` }
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in.Revision <= 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid revision"})
+		return
+	}
+	rv, err := s.loadRevision(r.Context(), mw.Entity, mw.Key, in.Revision)
+	if err == pgx.ErrNoRows { writeJSON(w, http.StatusNotFound, map[string]any{"error": "revision not found"}); return }
+	if err != nil { writeErr(w, err); return }
+	mw.Values, mw.Mode, mw.Source, mw.RevertedFrom = rv.Snapshot, replaceAll, "revert", &in.Revision
+	rev, err := s.writeMetadata(r.Context(), mw)
+	if err != nil { writeErr(w, err); return }
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "revision": rev, "changed": rev != 0})
+}
+
+func (s *Server) isAdmin(addr string) bool { return s.Config != nil && s.Config.IsAdmin(addr) }
+
+// GET /pools/{pool}/metadata/history
+func (s *Server) handlePoolMetadataHistory(w http.ResponseWriter, r *http.Request) {
+	pa, ok := s.loadPoolAuthority(w, r, extractBetween(r.URL.Path, "/pools/", "/metadata/history"))
+	if !ok { return }
+	s.writeHistory(w, r, poolMetadataEntity, pa.Pool)
+}
+
+// POST /pools/{pool}/metadata/revert {"revision"} (creator or admin)
+func (s *Server) handlePoolMetadataRevert(w http.ResponseWriter, r *http.Request) {
+	addr, ok := s.requireAuth(w, r)
+	if !ok { return }
+	pa, ok := s.loadPoolAuthority(w, r, extractBetween(r.URL.Path, "/pools/", "/metadata/revert"))
+	if !ok { return }
+	if !pa.isCreator(addr) && !s.isAdmin(addr) {
+		writeJSON(w, http.StatusForbidden, map[string]any{"error": "only the pool creator or an admin may revert metadata"})
+		return
+	}
+	s.revertTo(w, r, metadataWrite{Entity: poolMetadataEntity, Key: pa.Pool, Editor: addr,
+		Extra:      []colValue{{"token_address", pa.Token}, {"updated_by", addr}},
+		InsertOnly: []colValue{{"created_by", addr}}})
+}
+
+// GET /profiles/{address}/history
+func (s *Server) handleProfileHistory(w http.ResponseWriter, r *http.Request) {
+	s.writeHistory(w, r, profileEntity, strings.ToLower(extractBetween(r.URL.Path, "/profiles/", "/history")))
+}
+
+// POST /profiles/{address}/revert {"revision"} (profile owner or admin)
+func (s *Server) handleProfileRevert(w http.ResponseWriter, r *http.Request) {
+	addr, ok := s.requireAuth(w, r)
+	if !ok { return }
+	owner := strings.ToLower(extractBetween(r.URL.Path, "/profiles/", "/revert"))
+	if !strings.EqualFold(owner, addr) && !s.isAdmin(addr) {
+		writeJSON(w, http.StatusForbidden, map[string]any{"error": "only the profile owner or an admin may revert it"})
+		return
+	}
+	s.revertTo(w, r, metadataWrite{Entity: profileEntity, Key: owner, Editor: addr})
+}
@@ -1,7 +1,6 @@
 package api
 
 import (
-	"context"
 	"crypto/ecdsa"
 	"crypto/rand"
 	"encoding/hex"
@@ -10,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -19,12 +19,24 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/paxeer/offchain-server/internal/config"
+	"github.com/paxeer/offchain-server/internal/indexer"
 )
 
 type Server struct {
-	DB *pgxpool.Pool
+	DB     *pgxpool.Pool
+	Config *config.Config
+	ABIs   *indexer.ABIs
+	// RPC is optional; features needing chain reads degrade without it.
+	RPC bind.ContractCaller
+
+	stats *statsCache
+	// trustedProxies may set X-Forwarded-For (api.trustedProxies).
+	trustedProxies []*net.IPNet
 }
 
 // POST /profiles/bootstrap (unauthenticated)
@@ -43,7 +55,8 @@ func (s *Server) handleProfilesBootstrap(w http.ResponseWriter, r *http.Request)
     if len(uname) > 12 { uname = uname[:12] }
     if in.Username != nil && strings.TrimSpace(*in.Username) != "" { uname = strings.TrimSpace(*in.Username) }
     avatar := "https://api.dicebear.com/7.x/identicon/svg?seed=" + url.QueryEscape(addr)
-    _, _ = s.DB.Exec(r.Context(), `INSERT INTO profiles(address, username, avatar_url) VALUES($1,$2,$3) ON CONFLICT(address) DO NOTHING`, addr, uname, avatar)
+    _, _ = s.writeMetadata(r.Context(), metadataWrite{Entity: profileEntity, Key: addr, Mode: createOnly, Source: "bootstrap",
+        Values: map[string]*string{"username": &uname, "avatar_url": &avatar}})
     _ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "address": addr})
 }
 
@@ -125,12 +138,10 @@ func (s *Server) handleUpdateProfile(w http.ResponseWriter, r *http.Request) {
     if err := json.NewDecoder(r.Body).Decode(&in); err != nil { writeErr(w, err); return }
     if in.Username != nil && len(*in.Username) > 30 { writeJSON(w, http.StatusBadRequest, map[string]any{"error":"username too long"}); return }
     if in.Bio != nil && len(*in.Bio) > 160 { writeJSON(w, http.StatusBadRequest, map[string]any{"error":"bio too long"}); return }
-    _, err := s.DB.Exec(r.Context(), `INSERT INTO profiles(address, username, bio, avatar_url, banner_url, website_url, twitter_url, telegram_url)
-        VALUES($1,$2,$3,$4,$5,$6,$7,$8)
-        ON CONFLICT(address) DO UPDATE SET username=COALESCE(EXCLUDED.username, profiles.username), bio=COALESCE(EXCLUDED.bio, profiles.bio), avatar_url=COALESCE(EXCLUDED.avatar_url, profiles.avatar_url), banner_url=COALESCE(EXCLUDED.banner_url, profiles.banner_url), website_url=COALESCE(EXCLUDED.website_url, profiles.website_url), twitter_url=COALESCE(EXCLUDED.twitter_url, profiles.twitter_url), telegram_url=COALESCE(EXCLUDED.telegram_url, profiles.telegram_url), updated_at=NOW()`,
-        addr, in.Username, in.Bio, in.AvatarURL, in.BannerURL, in.WebsiteURL, in.TwitterURL, in.TelegramURL)
+    rev, err := s.writeMetadata(r.Context(), metadataWrite{Entity: profileEntity, Key: addr, Mode: mergeSet, Editor: addr, Source: "api",
+        Values: map[string]*string{"username": in.Username, "bio": in.Bio, "avatar_url": in.AvatarURL, "banner_url": in.BannerURL, "website_url": in.WebsiteURL, "twitter_url": in.TwitterURL, "telegram_url": in.TelegramURL}})
     if err != nil { writeErr(w, err); return }
-    writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+    writeJSON(w, http.StatusOK, map[string]any{"ok": true, "revision": rev})
 }
 
 
@@ -228,30 +239,31 @@ func (s *Server) proxyJSON2(w http.ResponseWriter, r *http.Request, target strin
 func (s *Server) handleGetPoolMetadata(w http.ResponseWriter, r *http.Request) {
     pool := extractBetween(r.URL.Path, "/pools/", "/metadata")
     if pool == "" { writeJSON(w, http.StatusBadRequest, map[string]any{"error":"missing pool"}); return }
-    var out struct{ Pool, Token string; Name, Symbol, Description, WebsiteURL, TwitterURL, TelegramURL, LogoURL, BannerURL, CreatedBy *string; UpdatedAt *time.Time }
-    err := s.DB.QueryRow(r.Context(), `SELECT pool_address, token_address, name, symbol, description, website_url, twitter_url, telegram_url, logo_url, banner_url, created_by, updated_at FROM pool_metadata WHERE pool_address=$1`, pool).
-        Scan(&out.Pool, &out.Token, &out.Name, &out.Symbol, &out.Description, &out.WebsiteURL, &out.TwitterURL, &out.TelegramURL, &out.LogoURL, &out.BannerURL, &out.CreatedBy, &out.UpdatedAt)
+    if common.IsHexAddress(pool) { pool = common.HexToAddress(pool).Hex() }
+    var out struct{ Pool, Token string; Name, Symbol, Description, WebsiteURL, TwitterURL, TelegramURL, LogoURL, BannerURL, CreatedBy, UpdatedBy *string; UpdatedAt *time.Time }
+    err := s.DB.QueryRow(r.Context(), `SELECT pool_address, token_address, name, symbol, description, website_url, twitter_url, telegram_url, logo_url, banner_url, created_by, updated_by, updated_at FROM pool_metadata WHERE pool_address=$1`, pool).
+        Scan(&out.Pool, &out.Token, &out.Name, &out.Symbol, &out.Description, &out.WebsiteURL, &out.TwitterURL, &out.TelegramURL, &out.LogoURL, &out.BannerURL, &out.CreatedBy, &out.UpdatedBy, &out.UpdatedAt)
     if err != nil { writeJSON(w, http.StatusOK, map[string]any{"pool": pool}); return }
     _ = json.NewEncoder(w).Encode(out)
 }
 
+// handleUpsertPoolMetadata is limited to the pool's on-chain creator and its
+// delegated editors; the token address always comes from the pools table.
 func (s *Server) handleUpsertPoolMetadata(w http.ResponseWriter, r *http.Request) {
-    addr, ok := s.requireAuth(w, r)
-    if !ok { return }
     pool := extractBetween(r.URL.Path, "/pools/", "/metadata")
     if pool == "" { writeJSON(w, http.StatusBadRequest, map[string]any{"error":"missing pool"}); return }
-    var in struct{ Token string; Name, Symbol, Description, WebsiteURL, TwitterURL, TelegramURL, LogoURL, BannerURL *string }
+    pa, addr, ok := s.requirePoolEditor(w, r, pool)
+    if !ok { return }
+    var in struct{ Name, Symbol, Description, WebsiteURL, TwitterURL, TelegramURL, LogoURL, BannerURL *string }
     if err := json.NewDecoder(r.Body).Decode(&in); err != nil { writeErr(w, err); return }
-    if in.Token == "" { writeJSON(w, http.StatusBadRequest, map[string]any{"error":"missing token"}); return }
     if in.Name != nil && len(*in.Name) > 50 { writeJSON(w, http.StatusBadRequest, map[string]any{"error":"name too long"}); return }
     if in.Symbol != nil && !regexp.MustCompile(`^[A-Z]{3,10}$`).MatchString(*in.Symbol) { writeJSON(w, http.StatusBadRequest, map[string]any{"error":"invalid symbol"}); return }
     if in.Description != nil && len(*in.Description) > 500 { writeJSON(w, http.StatusBadRequest, map[string]any{"error":"description too long"}); return }
-    _, err := s.DB.Exec(r.Context(), `INSERT INTO pool_metadata(pool_address, token_address, name, symbol, description, website_url, twitter_url, telegram_url, logo_url, banner_url, created_by)
-        VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
-        ON CONFLICT(pool_address) DO UPDATE SET name=COALESCE(EXCLUDED.name, pool_metadata.name), symbol=COALESCE(EXCLUDED.symbol, pool_metadata.symbol), description=COALESCE(EXCLUDED.description, pool_metadata.description), website_url=COALESCE(EXCLUDED.website_url, pool_metadata.website_url), twitter_url=COALESCE(EXCLUDED.twitter_url, pool_metadata.twitter_url), telegram_url=COALESCE(EXCLUDED.telegram_url, pool_metadata.telegram_url), logo_url=COALESCE(EXCLUDED.logo_url, pool_metadata.logo_url), banner_url=COALESCE(EXCLUDED.banner_url, pool_metadata.banner_url), updated_at=NOW()`,
-        pool, in.Token, in.Name, in.Symbol, in.Description, in.WebsiteURL, in.TwitterURL, in.TelegramURL, in.LogoURL, in.BannerURL, addr)
+    rev, err := s.writeMetadata(r.Context(), metadataWrite{Entity: poolMetadataEntity, Key: pa.Pool, Mode: mergeSet, Editor: addr, Source: "api",
+        Values: map[string]*string{"name": in.Name, "symbol": in.Symbol, "description": in.Description, "website_url": in.WebsiteURL, "twitter_url": in.TwitterURL, "telegram_url": in.TelegramURL, "logo_url": in.LogoURL, "banner_url": in.BannerURL},
+        Extra: []colValue{{"token_address", pa.Token}, {"updated_by", addr}}, InsertOnly: []colValue{{"created_by", addr}}})
     if err != nil { writeErr(w, err); return }
-    writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+    writeJSON(w, http.StatusOK, map[string]any{"ok": true, "revision": rev})
 }
 
 // GET /metadata/symbols?symbol=SYMB
@@ -384,24 +396,30 @@ func (s *Server) handleCommentsList(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "missing pool"})
 		return
 	}
-	limit := parseIntDefault(r.URL.Query().Get("limit"), 200)
-	rows, err := s.DB.Query(r.Context(), `SELECT author_address, message, created_at FROM comments WHERE pool_address = $1 ORDER BY created_at DESC LIMIT $2`, pool, limit)
+	p, err := parsePage(r, 200)
+	if err != nil { writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()}); return }
+	var c pageCursor
+	if p.Cursor != nil { c = *p.Cursor }
+	cond, order, limit, args := p.keyset([]string{"id"}, []any{pool}, c.ID)
+	rows, err := s.DB.Query(r.Context(), `SELECT id, author_address, message, created_at FROM comments WHERE pool_address = $1`+cond+` ORDER BY `+order+` LIMIT `+limit, args...)
 	if err != nil {
 		writeErr(w, err)
 		return
 	}
+	defer rows.Close()
 	type row struct {
+		ID int64 `json:"id"`
 		Author string `json:"author"`
 		Message string `json:"message"`
 		CreatedAt time.Time `json:"createdAt"`
 	}
-	var out []row
+	out := []row{}
 	for rows.Next() {
 		var rr row
-		_ = rows.Scan(&rr.Author, &rr.Message, &rr.CreatedAt)
+		_ = rows.Scan(&rr.ID, &rr.Author, &rr.Message, &rr.CreatedAt)
 		out = append(out, rr)
 	}
-	_ = json.NewEncoder(w).Encode(out)
+	writeJSON(w, http.StatusOK, pageOf(p, out, func(rr row) pageCursor { return pageCursor{ID: rr.ID} }))
 }
 
 func (s *Server) handleCommentsCount(w http.ResponseWriter, r *http.Request) {
@@ -479,34 +497,77 @@ func (s *Server) handleAuthNonce2(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(map[string]string{"nonce": nonce})
 }
 
+// POST /auth/verify {"message","signature"}
+// message is an EIP-4361 (SIWE) message carrying a nonce from /auth/nonce.
+// While auth.allowLegacyLogin is set, {"address","signature","nonce"} over
+// "paxeer-login:<nonce>" is still accepted.
 func (s *Server) handleAuthVerify(w http.ResponseWriter, r *http.Request) {
 	var in struct {
-		Address, Signature, Nonce string
+		Message, Address, Signature, Nonce string
 	}
 	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
 		writeErr(w, err)
 		return
 	}
-	if in.Address == "" || in.Signature == "" || in.Nonce == "" {
+	var address, nonce, msg string
+	switch {
+	case in.Message != "" && in.Signature != "":
+		rules := s.siweRules()
+		if len(rules.Domains) == 0 {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": "siwe login disabled: auth.domains is not configured"})
+			return
+		}
+		m, err := parseSIWE(in.Message)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		if err := m.validate(rules, time.Now()); err != nil {
+			writeJSON(w, http.StatusUnauthorized, map[string]any{"error": err.Error()})
+			return
+		}
+		address, nonce, msg = m.Address, m.Nonce, in.Message
+	case in.Address != "" && in.Signature != "" && in.Nonce != "":
+		if s.Config == nil || !s.Config.Auth.AllowLegacyLogin {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "legacy login disabled; sign a SIWE message"})
+			return
+		}
+		address, nonce, msg = in.Address, in.Nonce, "paxeer-login:"+in.Nonce
+	default:
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "missing fields"})
 		return
 	}
-	// Check nonce unused
+	// Check nonce unused and not expired
 	var used bool
-	if err := s.DB.QueryRow(r.Context(), `SELECT used FROM auth_nonces WHERE nonce=$1`, in.Nonce).Scan(&used); err != nil {
+	var issued time.Time
+	if err := s.DB.QueryRow(r.Context(), `SELECT used, created_at FROM auth_nonces WHERE nonce=$1`, nonce).Scan(&used, &issued); err != nil {
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid nonce"})
 		return
 	}
+	nonceCutoff := time.Now().Add(-s.nonceTTL())
+	if issued.Before(nonceCutoff) {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "nonce expired"})
+		return
+	}
 	if used {
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "nonce used"})
 		return
 	}
-	msg := "paxeer-login:" + in.Nonce
-	ok, err := verifyPersonalSign(in.Address, in.Signature, msg)
+	ok, err := s.verifySignature(r.Context(), address, in.Signature, msg)
 	if err != nil || !ok {
 		writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "invalid signature"})
 		return
 	}
+	// Consume the nonce; a concurrent verify with the same nonce loses here.
+	tag, err := s.DB.Exec(r.Context(), `UPDATE auth_nonces SET used=true WHERE nonce=$1 AND NOT used AND created_at >= $2`, nonce, nonceCutoff)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "nonce used"})
+		return
+	}
 	// Create session
 	token, err := randomHex(32)
 	if err != nil {
@@ -514,16 +575,17 @@ func (s *Server) handleAuthVerify(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	expires := time.Now().Add(30 * 24 * time.Hour)
-	if _, err := s.DB.Exec(r.Context(), `INSERT INTO sessions(token, address, expires_at) VALUES($1,$2,$3)`, token, strings.ToLower(in.Address), expires); err != nil {
+	if _, err := s.DB.Exec(r.Context(), `INSERT INTO sessions(token, address, expires_at, user_agent, ip, last_seen_at) VALUES($1,$2,$3,$4,$5,NOW())`, token, strings.ToLower(address), expires, r.UserAgent(), s.clientIP(r)); err != nil {
 		writeErr(w, err)
 		return
 	}
 	http.SetCookie(w, &http.Cookie{ Name: "sid", Value: token, Path: "/", Expires: expires, HttpOnly: true, SameSite: http.SameSiteLaxMode })
-	addrLower := strings.ToLower(in.Address)
-	defaultUsername := "user-" + strings.ToLower(strings.TrimPrefix(in.Address, "0x"))
+	addrLower := strings.ToLower(address)
+	defaultUsername := "user-" + strings.ToLower(strings.TrimPrefix(address, "0x"))
 	if len(defaultUsername) > 12 { defaultUsername = defaultUsername[:12] }
-	defaultAvatar := "https://api.dicebear.com/7.x/identicon/svg?seed=" + url.QueryEscape(in.Address)
-	_, _ = s.DB.Exec(r.Context(), `INSERT INTO profiles(address, username, avatar_url) VALUES($1,$2,$3) ON CONFLICT(address) DO NOTHING`, addrLower, defaultUsername, defaultAvatar)
+	defaultAvatar := "https://api.dicebear.com/7.x/identicon/svg?seed=" + url.QueryEscape(address)
+	_, _ = s.writeMetadata(r.Context(), metadataWrite{Entity: profileEntity, Key: addrLower, Mode: createOnly, Editor: addrLower, Source: "login",
+		Values: map[string]*string{"username": &defaultUsername, "avatar_url": &defaultAvatar}})
 	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "address": addrLower})
 }
 
@@ -537,8 +599,10 @@ func (s *Server) handleAuthMe(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleAuthLogout(w http.ResponseWriter, r *http.Request) {
-	if c, err := r.Cookie("sid"); err == nil {
-		_, _ = s.DB.Exec(r.Context(), `DELETE FROM sessions WHERE token=$1`, c.Value)
+	if token := sessionToken(r); token != "" {
+		_, _ = s.DB.Exec(r.Context(), `DELETE FROM sessions WHERE token=$1`, token)
+	}
+	if _, err := r.Cookie("sid"); err == nil {
 		http.SetCookie(w, &http.Cookie{ Name: "sid", Value: "", Path: "/", Expires: time.Unix(0,0), HttpOnly: true, SameSite: http.SameSiteLaxMode })
 	}
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
@@ -618,23 +682,65 @@ func (s *Server) proxyJSON(w http.ResponseWriter, r *http.Request, target string
 }
 
 // ===== Helpers =====
+// sessionToken is the request's session token: a bearer token that is not
+// an API key, else the sid cookie.
+func sessionToken(r *http.Request) string {
+	if t := bearerToken(r); t != "" {
+		if strings.HasPrefix(t, apiKeyPrefix) {
+			return ""
+		}
+		return t
+	}
+	if c, err := r.Cookie("sid"); err == nil {
+		return c.Value
+	}
+	return ""
+}
+
 func (s *Server) getSessionAddress(r *http.Request) (string, bool) {
-	c, err := r.Cookie("sid")
-	if err != nil {
+	token := sessionToken(r)
+	if token == "" {
 		return "", false
 	}
 	var addr string
 	var exp time.Time
-	if err := s.DB.QueryRow(r.Context(), `SELECT address, expires_at FROM sessions WHERE token=$1`, c.Value).Scan(&addr, &exp); err != nil {
+	var seen *time.Time
+	if err := s.DB.QueryRow(r.Context(), `SELECT address, expires_at, last_seen_at FROM sessions WHERE token=$1`, token).Scan(&addr, &exp, &seen); err != nil {
 		return "", false
 	}
 	if time.Now().After(exp) {
 		return "", false
 	}
+	if seen == nil || time.Since(*seen) > lastSeenGranularity {
+		_, _ = s.DB.Exec(r.Context(), `UPDATE sessions SET last_seen_at=NOW() WHERE token=$1`, token)
+	}
 	return addr, true
 }
 
+// requireAuth accepts a session (sid cookie or bearer token) or an API key
+// with the scope the request needs (see scopeFor).
 func (s *Server) requireAuth(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if addr, ok := s.getSessionAddress(r); ok {
+		return addr, true
+	}
+	if key := bearerToken(r); strings.HasPrefix(key, apiKeyPrefix) {
+		if addr, scopes, ok := s.apiKeyAddress(r, key); ok {
+			need := scopeFor(r)
+			for _, sc := range scopes {
+				if sc == need {
+					return addr, true
+				}
+			}
+			writeJSON(w, http.StatusForbidden, map[string]any{"error": "api key lacks scope " + need})
+			return "", false
+		}
+	}
+	writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "unauthorized"})
+	return "", false
+}
+
+// requireSession is requireAuth without API keys, for managing the keys themselves.
+func (s *Server) requireSession(w http.ResponseWriter, r *http.Request) (string, bool) {
 	addr, ok := s.getSessionAddress(r)
 	if !ok {
 		writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "unauthorized"})
@@ -705,30 +811,25 @@ func fmtDurationPG(d time.Duration) string {
     return fmt.Sprintf("%d seconds", seconds)
 }
 
-func New(db *pgxpool.Pool) *Server {
-	return &Server{DB: db}
+func New(db *pgxpool.Pool, cfg *config.Config, abis *indexer.ABIs, rpc bind.ContractCaller) *Server {
+	s := &Server{DB: db, Config: cfg, ABIs: abis, RPC: rpc, stats: newStatsCache()}
+	if cfg != nil {
+		s.trustedProxies = parseTrustedProxies(cfg.API.TrustedProxies)
+	}
+	return s
 }
 
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-    // CORS
-    origin := r.Header.Get("Origin")
-    if origin != "" {
-        // Echo explicit origin and allow credentials
-        w.Header().Set("Access-Control-Allow-Origin", origin)
-        w.Header().Set("Vary", "Origin")
-        w.Header().Set("Access-Control-Allow-Credentials", "true")
-    } else {
-        // Default wildcard for SSR or same-origin requests without Origin
-        w.Header().Set("Access-Control-Allow-Origin", "*")
-    }
-    w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-    w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PATCH,OPTIONS")
+	s.setCORSHeaders(w, r)
 	if r.Method == http.MethodOptions {
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	if !s.checkCSRF(w, r) {
+		return
+	}
 	switch {
 	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/uploads/"):
 		s.handleStaticUpload(w, r)
@@ -737,6 +838,8 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
 	case r.Method == http.MethodGet && r.URL.Path == "/pools":
 		s.handleListPools(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/pools/graduated":
+		s.handleGraduatedPools(w, r)
 	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/state"):
 		s.handlePoolState(w, r)
 	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/price-updates"):
@@ -745,10 +848,36 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		s.handleCandles(w, r)
 	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/swaps"):
 		s.handleSwaps(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/liquidity"):
+		s.handleLiquidityEvents(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/oracle-updates"):
+		s.handleOracleUpdates(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/creator-fees"):
+		s.handleCreatorFees(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/reserves"):
+		s.handleReserves(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/twap"):
+		s.handleTWAP(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/progress"):
+		s.handlePoolProgress(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/stats"):
+		s.handlePoolStats(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/quote"):
+		s.handleQuote(w, r)
 	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/metadata"):
 		s.handleGetPoolMetadata(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/metadata/history"):
+		s.handlePoolMetadataHistory(w, r)
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/metadata/revert"):
+		s.handlePoolMetadataRevert(w, r)
 	case (r.Method == http.MethodPost || r.Method == http.MethodPut || r.Method == http.MethodPatch) && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/metadata"):
 		s.handleUpsertPoolMetadata(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/editors"):
+		s.handleListPoolEditors(w, r)
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/pools/") && strings.HasSuffix(r.URL.Path, "/editors"):
+		s.handleAddPoolEditor(w, r)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/pools/") && strings.Contains(r.URL.Path, "/editors/"):
+		s.handleRemovePoolEditor(w, r)
 	case r.Method == http.MethodGet && r.URL.Path == "/metadata/symbols":
 		s.handleSymbolExists(w, r)
 	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/comments/") && !strings.HasSuffix(r.URL.Path, "/count"):
@@ -757,7 +886,20 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		s.handleCommentsCount(w, r)
 	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/comments/"):
 		s.handleCommentsCreate(w, r)
+	// Pre-launch metadata drafts
+	case r.Method == http.MethodGet && r.URL.Path == "/drafts":
+		s.handleListDrafts(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/drafts/"):
+		s.handleGetDraft(w, r)
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/drafts/"):
+		s.handlePutDraft(w, r)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/drafts/"):
+		s.handleDeleteDraft(w, r)
 	// Profiles
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/profiles/") && strings.HasSuffix(r.URL.Path, "/history"):
+		s.handleProfileHistory(w, r)
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/profiles/") && strings.HasSuffix(r.URL.Path, "/revert"):
+		s.handleProfileRevert(w, r)
 	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/profiles/"):
 		s.handleGetProfile(w, r)
 	case r.Method == http.MethodPatch && r.URL.Path == "/profiles":
@@ -773,6 +915,37 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		s.handleAuthMe(w, r)
 	case r.Method == http.MethodPost && r.URL.Path == "/auth/logout":
 		s.handleAuthLogout(w, r)
+	case r.Method == http.MethodPost && r.URL.Path == "/auth/logout-all":
+		s.handleLogoutAll(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/auth/sessions":
+		s.handleListSessions(w, r)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/auth/sessions/"):
+		s.handleRevokeSession(w, r)
+	// Unsigned router transactions
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/tx/"):
+		s.handleBuildTx(w, r)
+	// Accounts
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/accounts/") && strings.HasSuffix(r.URL.Path, "/portfolio"):
+		s.handlePortfolio(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/accounts/") && strings.HasSuffix(r.URL.Path, "/trades"):
+		s.handleAccountTrades(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/accounts/") && strings.HasSuffix(r.URL.Path, "/pnl"):
+		s.handleAccountPnL(w, r)
+	// Creators
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/creators/") && strings.HasSuffix(r.URL.Path, "/pools"):
+		s.handleCreatorPools(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/me/pools":
+		s.handleMyPools(w, r)
+	// API keys
+	case r.Method == http.MethodGet && r.URL.Path == "/me/api-keys":
+		s.handleListAPIKeys(w, r)
+	case r.Method == http.MethodPost && r.URL.Path == "/me/api-keys":
+		s.handleCreateAPIKey(w, r)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/me/api-keys/"):
+		s.handleRevokeAPIKey(w, r)
+	// Leaderboards
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/leaderboards/"):
+		s.handleLeaderboard(w, r)
 	// Uploads
 	case r.Method == http.MethodPost && r.URL.Path == "/upload":
 		s.handleUpload(w, r)
@@ -794,32 +967,6 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *Server) handleListPools(w http.ResponseWriter, r *http.Request) {
-	rows, err := s.DB.Query(r.Context(), `SELECT pool_address, token_address, oracle_address, created_block, created_tx, created_time, reserve_usdc, reserve_token, spot_x18, floor_x18 FROM pools ORDER BY created_block DESC`)
-	if err != nil {
-		writeErr(w, err)
-		return
-	}
-	type row struct {
-		Pool      string     `json:"pool"`
-		Token     string     `json:"token"`
-		Oracle    string     `json:"oracle"`
-		Block     int64      `json:"createdBlock"`
-		Tx        string     `json:"createdTx"`
-		Time      *time.Time `json:"createdTime,omitempty"`
-		ReserveUS string     `json:"reserveUSDC,omitempty"`
-		ReserveT  string     `json:"reserveToken,omitempty"`
-		SpotX18   string     `json:"spotX18,omitempty"`
-		FloorX18  string     `json:"floorX18,omitempty"`
-	}
-	var out []row
-	for rows.Next() {
-		var rr row
-		_ = rows.Scan(&rr.Pool, &rr.Token, &rr.Oracle, &rr.Block, &rr.Tx, &rr.Time, &rr.ReserveUS, &rr.ReserveT, &rr.SpotX18, &rr.FloorX18)
-		out = append(out, rr)
-	}
-	_ = json.NewEncoder(w).Encode(out)
-}
 
 func (s *Server) handlePoolState(w http.ResponseWriter, r *http.Request) {
 	pool := extractBetween(r.URL.Path, "/pools/", "/state")
@@ -830,31 +977,54 @@ func (s *Server) handlePoolState(w http.ResponseWriter, r *http.Request) {
 		Block     int64      `json:"createdBlock"`
 		Tx        string     `json:"createdTx"`
 		Time      *time.Time `json:"createdTime,omitempty"`
-		ReserveUS string     `json:"reserveUSDC,omitempty"`
-		ReserveT  string     `json:"reserveToken,omitempty"`
-		SpotX18   string     `json:"spotX18,omitempty"`
-		FloorX18  string     `json:"floorX18,omitempty"`
-	}
-	err := s.DB.QueryRow(r.Context(), `SELECT pool_address, token_address, oracle_address, created_block, created_tx, created_time, reserve_usdc, reserve_token, spot_x18, floor_x18 FROM pools WHERE pool_address = $1`, pool).Scan(&rr.Pool, &rr.Token, &rr.Oracle, &rr.Block, &rr.Tx, &rr.Time, &rr.ReserveUS, &rr.ReserveT, &rr.SpotX18, &rr.FloorX18)
+		ReserveUS *string    `json:"reserveUSDC,omitempty"`
+		ReserveT  *string    `json:"reserveToken,omitempty"`
+		SpotX18   *string    `json:"spotX18,omitempty"`
+		FloorX18  *string    `json:"floorX18,omitempty"`
+		ReserveUSFormatted *string `json:"reserveUSDCFormatted,omitempty"`
+		ReserveTFormatted  *string `json:"reserveTokenFormatted,omitempty"`
+		Price      *string `json:"price,omitempty"`
+		FloorPrice *string `json:"floorPrice,omitempty"`
+		TotalSupply   *string `json:"totalSupply"`
+		Circulating   *string `json:"circulatingSupply"`
+		MarketCapUSDC *string `json:"marketCapUSDC"`
+		FDVUSDC       *string `json:"fdvUSDC"`
+		LiquidityUSDC string  `json:"liquidityUSDC"`
+		poolDecimals
+	}
+	err := s.DB.QueryRow(r.Context(), `SELECT p.pool_address, p.token_address, p.oracle_address, p.created_block, p.created_tx, p.created_time, p.reserve_usdc, p.reserve_token, p.spot_x18, p.floor_x18, pv.total_supply, pv.circulating_supply, pv.market_cap, pv.fdv, pv.tvl FROM pools p JOIN pool_valuations pv ON pv.pool_address = p.pool_address WHERE p.pool_address = $1`, pool).Scan(&rr.Pool, &rr.Token, &rr.Oracle, &rr.Block, &rr.Tx, &rr.Time, &rr.ReserveUS, &rr.ReserveT, &rr.SpotX18, &rr.FloorX18, &rr.TotalSupply, &rr.Circulating, &rr.MarketCapUSDC, &rr.FDVUSDC, &rr.LiquidityUSDC)
 	if err != nil {
 		writeErr(w, err)
 		return
 	}
+	rr.poolDecimals = s.poolDecimals(r.Context(), pool)
+	rr.ReserveUSFormatted = formatUnitsPtr(rr.ReserveUS, rr.USDCDecimals)
+	rr.ReserveTFormatted = formatUnitsPtr(rr.ReserveT, rr.TokenDecimals)
+	rr.Price = rr.pricePtr(rr.SpotX18)
+	rr.FloorPrice = rr.pricePtr(rr.FloorX18)
 	_ = json.NewEncoder(w).Encode(rr)
 }
 
 func (s *Server) handlePriceUpdates(w http.ResponseWriter, r *http.Request) {
 	pool := extractBetween(r.URL.Path, "/pools/", "/price-updates")
-	limit := parseIntDefault(r.URL.Query().Get("limit"), 200)
+	p, err := parsePage(r, 200)
+	if err != nil { writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()}); return }
 	fromBlock := parseIntDefault(r.URL.Query().Get("fromBlock"), 0)
-	rows, err := s.DB.Query(r.Context(), `SELECT price_x18, floor_x18, block_number, tx_hash, log_index, block_time FROM price_updates WHERE pool_address = $1 AND block_number >= $2 ORDER BY block_number DESC, log_index DESC LIMIT $3`, pool, fromBlock, limit)
+	var c pageCursor
+	if p.Cursor != nil { c = *p.Cursor }
+	cond, order, limit, args := p.keyset([]string{"block_number", "log_index"}, []any{pool, fromBlock}, c.Block, c.Log)
+	rows, err := s.DB.Query(r.Context(), `SELECT price_x18, floor_x18, block_number, tx_hash, log_index, block_time FROM price_updates WHERE pool_address = $1 AND block_number >= $2`+cond+` ORDER BY `+order+` LIMIT `+limit, args...)
 	if err != nil {
 		writeErr(w, err)
 		return
 	}
+	defer rows.Close()
+	dec := s.poolDecimals(r.Context(), pool)
 	type row struct{
 		PriceX18 string `json:"priceX18"`
 		FloorX18 string `json:"floorX18"`
+		Price string `json:"price"`
+		FloorPrice string `json:"floorPrice"`
 		Block int64 `json:"blockNumber"`
 		Tx string `json:"txHash"`
 		LogIndex int `json:"logIndex"`
@@ -865,21 +1035,30 @@ func (s *Server) handlePriceUpdates(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var rr row
 		_ = rows.Scan(&rr.PriceX18, &rr.FloorX18, &rr.Block, &rr.Tx, &rr.LogIndex, &rr.Time)
+		rr.Price, rr.FloorPrice = dec.price(rr.PriceX18), dec.price(rr.FloorX18)
 		out = append(out, rr)
 	}
-	_ = json.NewEncoder(w).Encode(out)
+	writeJSON(w, http.StatusOK, pageOf(p, out, func(rr row) pageCursor { return eventCursor(rr.Block, rr.LogIndex) }))
 }
 
 func (s *Server) handleSwaps(w http.ResponseWriter, r *http.Request) {
 	pool := extractBetween(r.URL.Path, "/pools/", "/swaps")
-	limit := parseIntDefault(r.URL.Query().Get("limit"), 100)
-	rows, err := s.DB.Query(r.Context(), `SELECT sender, usdc_to_token, amount_in, amount_out, recipient, block_number, tx_hash, log_index, block_time FROM swaps WHERE pool_address = $1 ORDER BY block_number DESC, log_index DESC LIMIT $2`, pool, limit)
+	p, err := parsePage(r, 100)
+	if err != nil { writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()}); return }
+	var c pageCursor
+	if p.Cursor != nil { c = *p.Cursor }
+	cond, order, limit, args := p.keyset([]string{"block_number", "log_index"}, []any{pool}, c.Block, c.Log)
+	rows, err := s.DB.Query(r.Context(), `SELECT sender, usdc_to_token, amount_in, amount_out, recipient, block_number, tx_hash, log_index, block_time FROM swaps WHERE pool_address = $1`+cond+` ORDER BY `+order+` LIMIT `+limit, args...)
 	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	dec := s.poolDecimals(r.Context(), pool)
 	type row struct{
 		Sender string `json:"sender"`
 		USDCToToken bool `json:"usdcToToken"`
 		AmountIn string `json:"amountIn"`
 		AmountOut string `json:"amountOut"`
+		AmountInFormatted string `json:"amountInFormatted"`
+		AmountOutFormatted string `json:"amountOutFormatted"`
 		Recipient string `json:"recipient"`
 		Block int64 `json:"blockNumber"`
 		Tx string `json:"txHash"`
@@ -891,57 +1070,14 @@ func (s *Server) handleSwaps(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var rr row
 		_ = rows.Scan(&rr.Sender, &rr.USDCToToken, &rr.AmountIn, &rr.AmountOut, &rr.Recipient, &rr.Block, &rr.Tx, &rr.LogIndex, &rr.Time)
+		if rr.USDCToToken {
+			rr.AmountInFormatted, rr.AmountOutFormatted = dec.usdc(rr.AmountIn), dec.token(rr.AmountOut)
+		} else {
+			rr.AmountInFormatted, rr.AmountOutFormatted = dec.token(rr.AmountIn), dec.usdc(rr.AmountOut)
+		}
 		out = append(out, rr)
 	}
-	_ = json.NewEncoder(w).Encode(out)
-}
-
-func (s *Server) handleCandles(w http.ResponseWriter, r *http.Request) {
-	pool := extractBetween(r.URL.Path, "/pools/", "/candles")
-	interval := r.URL.Query().Get("interval")
-	if interval == "" { interval = "5m" }
-	bucket, ok := bucketSeconds(interval)
-	if !ok {
-		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid interval"})
-		return
-	}
-	limit := parseIntDefault(r.URL.Query().Get("limit"), 200)
-	t := struct{
-		Bucket int64
-		Pool string
-		Limit int
-	}{bucket, pool, limit}
-	query := `
-	WITH b AS (
-		SELECT to_timestamp(floor(extract(epoch from coalesce(block_time, now())) / $1) * $1) AS bucket_time,
-		       price_x18, block_time
-		FROM price_updates WHERE pool_address = $2
-	), o AS (
-		SELECT bucket_time,
-			(ARRAY_AGG(price_x18 ORDER BY block_time ASC))[1] AS open,
-			MAX(price_x18) AS high,
-			MIN(price_x18) AS low,
-			(ARRAY_AGG(price_x18 ORDER BY block_time DESC))[1] AS close
-		FROM b GROUP BY bucket_time ORDER BY bucket_time DESC LIMIT $3
-	)
-	SELECT bucket_time, open, high, low, close FROM o ORDER BY bucket_time ASC`
-	rows, err := s.DB.Query(context.Background(), query, t.Bucket, t.Pool, t.Limit)
-	if err != nil { writeErr(w, err); return }
-	type row struct{
-		BucketTime time.Time `json:"bucketTime"`
-		Open string `json:"open"`
-		High string `json:"high"`
-		Low string `json:"low"`
-		Close string `json:"close"`
-	}
-	var out []row
-	out = []row{}
-	for rows.Next() {
-		var rr row
-		_ = rows.Scan(&rr.BucketTime, &rr.Open, &rr.High, &rr.Low, &rr.Close)
-		out = append(out, rr)
-	}
-	_ = json.NewEncoder(w).Encode(out)
+	writeJSON(w, http.StatusOK, pageOf(p, out, func(rr row) pageCursor { return eventCursor(rr.Block, rr.LogIndex) }))
 }
 
 func writeErr(w http.ResponseWriter, err error) {
@@ -969,6 +1105,14 @@ func parseIntDefault(s string, def int) int {
 	return i
 }
 
+// parseTimeParam accepts either unix seconds or an RFC3339 timestamp.
+func parseTimeParam(s string) (time.Time, bool) {
+	if s == "" { return time.Time{}, false }
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil { return time.Unix(sec, 0).UTC(), true }
+	if t, err := time.Parse(time.RFC3339, s); err == nil { return t.UTC(), true }
+	return time.Time{}, false
+}
+
 var bucketRe = regexp.MustCompile(`^(\d+)([mhd])$`)
 
 func bucketSeconds(s string) (int64, bool) {
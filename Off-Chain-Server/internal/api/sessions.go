@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nonceTTL is how long a nonce from /auth/nonce stays valid.
+func (s *Server) nonceTTL() time.Duration {
+	if s.Config == nil || s.Config.Auth.NonceTTLSecs == 0 {
+		return 10 * time.Minute
+	}
+	return time.Duration(s.Config.Auth.NonceTTLSecs) * time.Second
+}
+
+// lastSeenGranularity limits last_seen_at writes to one per session per minute.
+const lastSeenGranularity = time.Minute
+
+type sessionInfo struct {
+	ID         int64      `json:"id"`
+	UserAgent  *string    `json:"userAgent"`
+	IP         *string    `json:"ip"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastSeenAt *time.Time `json:"lastSeenAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	Current    bool       `json:"current"`
+}
+
+// GET /auth/sessions: the caller's live sessions, most recently used first.
+func (s *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	addr, ok := s.requireSession(w, r)
+	if !ok { return }
+	rows, err := s.DB.Query(r.Context(), `
+		SELECT id, user_agent, ip, created_at, last_seen_at, expires_at, token = $2
+		FROM sessions WHERE address = $1 AND expires_at > NOW()
+		ORDER BY COALESCE(last_seen_at, created_at) DESC`, addr, sessionToken(r))
+	if err != nil { writeErr(w, err); return }
+	defer rows.Close()
+	out := []sessionInfo{}
+	for rows.Next() {
+		var si sessionInfo
+		if err := rows.Scan(&si.ID, &si.UserAgent, &si.IP, &si.CreatedAt, &si.LastSeenAt, &si.ExpiresAt, &si.Current); err != nil { writeErr(w, err); return }
+		out = append(out, si)
+	}
+	if err := rows.Err(); err != nil { writeErr(w, err); return }
+	writeJSON(w, http.StatusOK, out)
+}
+
+// DELETE /auth/sessions/{id}
+func (s *Server) handleRevokeSession(w http.ResponseWriter, r *http.Request) {
+	addr, ok := s.requireSession(w, r)
+	if !ok { return }
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/auth/sessions/"), 10, 64)
+	if err != nil { writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid id"}); return }
+	tag, err := s.DB.Exec(r.Context(), `DELETE FROM sessions WHERE id = $1 AND address = $2`, id, addr)
+	if err != nil { writeErr(w, err); return }
+	if tag.RowsAffected() == 0 { writeJSON(w, http.StatusNotFound, map[string]any{"error": "session not found"}); return }
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+}
+
+// POST /auth/logout-all: ends every session of the caller, this one included.
+func (s *Server) handleLogoutAll(w http.ResponseWriter, r *http.Request) {
+	addr, ok := s.requireSession(w, r)
+	if !ok { return }
+	tag, err := s.DB.Exec(r.Context(), `DELETE FROM sessions WHERE address = $1`, addr)
+	if err != nil { writeErr(w, err); return }
+	if _, err := r.Cookie("sid"); err == nil {
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "", Path: "/", Expires: time.Unix(0, 0), HttpOnly: true, SameSite: http.SameSiteLaxMode})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "revoked": tag.RowsAffected()})
+}
+
+// CleanupAuthEvery deletes expired sessions and nonces older than the nonce
+// TTL now and then on every interval until ctx is done.
+func (s *Server) CleanupAuthEvery(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if _, err := s.DB.Exec(ctx, `DELETE FROM sessions WHERE expires_at <= NOW()`); err != nil && ctx.Err() == nil {
+			log.Printf("[warn] delete expired sessions: %v", err)
+		}
+		if _, err := s.DB.Exec(ctx, `DELETE FROM auth_nonces WHERE created_at < $1`, time.Now().Add(-s.nonceTTL())); err != nil && ctx.Err() == nil {
+			log.Printf("[warn] delete expired nonces: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// erc1271Magic is isValidSignature(bytes32,bytes)'s selector, returned by
+// EIP-1271 wallets for a valid signature.
+var erc1271Magic = [4]byte{0x16, 0x26, 0xba, 0x7e}
+
+// erc6492Suffix ends signatures of counterfactual (not yet deployed) wallets.
+var erc6492Suffix = common.FromHex("0x6492649264926492649264926492649264926492649264926492649264926492")
+
+var (
+	bytes32Type, _ = abi.NewType("bytes32", "", nil)
+	bytesType, _   = abi.NewType("bytes", "", nil)
+	addressType, _ = abi.NewType("address", "", nil)
+
+	isValidSignatureArgs = abi.Arguments{{Type: bytes32Type}, {Type: bytesType}}
+	// erc6492Wrapper is abi.encode(factory, factoryCalldata, signature).
+	erc6492Wrapper = abi.Arguments{{Type: addressType}, {Type: bytesType}, {Type: bytesType}}
+)
+
+// deploylessValidator is init code run with eth_call and no recipient. Its
+// constructor calls factory with factoryCalldata (deploying the wallet), then
+// staticcalls signer with isValidSignatureCalldata, and returns a 32-byte 1
+// when the call succeeded with the EIP-1271 magic value, else 0. It expects
+// appended data: factory, signer, len(factoryCalldata),
+// len(isValidSignatureCalldata) as 32-byte words, followed by both calldatas.
+var deploylessValidator = common.FromHex("0x" +
+	"6047380360476000" + "39" + // codecopy(0, 71, codesize-71)
+	"6000600060405160806000600051" + "5af150" + // pop(call(gas, factory, 0, 0x80, lenA, 0, 0))
+	"60206000606051604051608001602051" + "5afa" + // staticcall(gas, signer, 0x80+lenA, lenB, 0, 0x20)
+	"60203d101516" + // and(success, iszero(lt(returndatasize, 0x20)))
+	"60005160e01c631626ba7e1416" + // and(.., eq(shr(224, mload(0)), magic))
+	"600052" + "60206000f3") // mstore(0, ..) return(0, 0x20)
+
+// verifySignature checks a personal_sign signature by address over message.
+// Plain ECDSA is tried first; when it does not match and an RPC is
+// configured, the address is treated as a contract wallet: EIP-1271
+// isValidSignature is called on it, or for an EIP-6492 wrapped signature of a
+// wallet that is not deployed yet, its deployment is simulated in the same
+// eth_call.
+func verifySignature(ctx context.Context, caller bind.ContractCaller, address, signatureHex, message string) (bool, error) {
+	if !common.IsHexAddress(address) {
+		return false, errors.New("invalid address")
+	}
+	signer := common.HexToAddress(address)
+	sig, err := hex.DecodeString(strings.TrimPrefix(signatureHex, "0x"))
+	if err != nil {
+		return false, err
+	}
+	hash := common.BytesToHash(accounts.TextHash([]byte(message)))
+
+	wrapped := len(sig) > len(erc6492Suffix) && bytes.HasSuffix(sig, erc6492Suffix)
+	if !wrapped && len(sig) == 65 {
+		if ok, err := verifyPersonalSign(address, signatureHex, message); err == nil && ok {
+			return true, nil
+		}
+	}
+	if caller == nil {
+		return false, nil
+	}
+	code, err := caller.CodeAt(ctx, signer, nil)
+	if err != nil {
+		return false, err
+	}
+	if !wrapped {
+		if len(code) == 0 {
+			return false, nil
+		}
+		return isValidSignature(ctx, caller, signer, hash, sig)
+	}
+
+	vals, err := erc6492Wrapper.Unpack(sig[:len(sig)-len(erc6492Suffix)])
+	if err != nil {
+		return false, fmt.Errorf("invalid EIP-6492 signature: %w", err)
+	}
+	factory, _ := vals[0].(common.Address)
+	factoryCalldata, _ := vals[1].([]byte)
+	inner, _ := vals[2].([]byte)
+	if len(code) > 0 {
+		return isValidSignature(ctx, caller, signer, hash, inner)
+	}
+	return deploylessIsValidSignature(ctx, caller, factory, factoryCalldata, signer, hash, inner)
+}
+
+func isValidSignatureCalldata(hash common.Hash, sig []byte) ([]byte, error) {
+	args, err := isValidSignatureArgs.Pack(hash, sig)
+	if err != nil {
+		return nil, err
+	}
+	return append(erc1271Magic[:], args...), nil
+}
+
+// isValidSignature calls EIP-1271 isValidSignature on a deployed wallet. A
+// reverting call is an invalid signature, not an error.
+func isValidSignature(ctx context.Context, caller bind.ContractCaller, wallet common.Address, hash common.Hash, sig []byte) (bool, error) {
+	data, err := isValidSignatureCalldata(hash, sig)
+	if err != nil {
+		return false, err
+	}
+	out, err := caller.CallContract(ctx, ethereum.CallMsg{To: &wallet, Data: data}, nil)
+	if err != nil {
+		return false, nil
+	}
+	return len(out) >= 4 && bytes.Equal(out[:4], erc1271Magic[:]), nil
+}
+
+// deploylessIsValidSignature runs deploylessValidator to check a signature
+// of a counterfactual wallet without deploying it on-chain.
+func deploylessIsValidSignature(ctx context.Context, caller bind.ContractCaller, factory common.Address, factoryCalldata []byte, wallet common.Address, hash common.Hash, sig []byte) (bool, error) {
+	call, err := isValidSignatureCalldata(hash, sig)
+	if err != nil {
+		return false, err
+	}
+	data := append([]byte{}, deploylessValidator...)
+	data = append(data, common.LeftPadBytes(factory.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(wallet.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(int64(len(factoryCalldata))).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(int64(len(call))).Bytes(), 32)...)
+	data = append(data, factoryCalldata...)
+	data = append(data, call...)
+	out, err := caller.CallContract(ctx, ethereum.CallMsg{Data: data}, nil)
+	if err != nil {
+		return false, err
+	}
+	return len(out) == 32 && out[31] == 1, nil
+}
+
+// verifySignature is the server's entry point, using its RPC when set.
+func (s *Server) verifySignature(ctx context.Context, address, signatureHex, message string) (bool, error) {
+	return verifySignature(ctx, s.RPC, address, signatureHex, message)
+}
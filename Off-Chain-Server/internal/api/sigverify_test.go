@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// evmCaller is a bind.ContractCaller over an in-memory state. Calls run on
+// a snapshot that is reverted afterwards, like eth_call.
+type evmCaller struct {
+	state *state.StateDB
+}
+
+func newEVMCaller(t *testing.T) *evmCaller {
+	t.Helper()
+	st, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &evmCaller{state: st}
+}
+
+func (c *evmCaller) CodeAt(_ context.Context, addr common.Address, _ *big.Int) ([]byte, error) {
+	return c.state.GetCode(addr), nil
+}
+
+func (c *evmCaller) CallContract(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	snap := c.state.Snapshot()
+	defer c.state.RevertToSnapshot(snap)
+	cfg := &runtime.Config{State: c.state, GasLimit: 10_000_000}
+	if msg.To == nil {
+		out, _, _, err := runtime.Create(msg.Data, cfg)
+		return out, err
+	}
+	out, _, err := runtime.Call(*msg.To, msg.Data, cfg)
+	return out, err
+}
+
+// ownerWallet is the runtime code of a minimal EIP-1271 wallet: it
+// ecrecovers isValidSignature(hash, sig) and returns the magic value when
+// the signer is owner, else zero.
+func ownerWallet(owner common.Address) []byte {
+	code := common.FromHex("0x" +
+		"600435600052" + // mstore(0, hash)
+		"60a43560f81c602052" + // mstore(0x20, v)
+		"606435604052" + // mstore(0x40, r)
+		"608435606052" + // mstore(0x60, s)
+		"602060806080600060015afa50" + // pop(staticcall(gas, 1, 0, 0x80, 0x80, 0x20))
+		"60805173") // mload(0x80), push20 owner
+	code = append(code, owner.Bytes()...)
+	return append(code, common.FromHex("0x"+
+		"14631626ba7e0260e01b"+ // mul(eq(..), magic) << 224
+		"60005260206000f3")...) // return(0, 0x20)
+}
+
+// revertingWallet reverts every call.
+var revertingWallet = common.FromHex("0x60006000fd")
+
+// create2Factory deploys its calldata as init code with CREATE2 and salt 0.
+var create2Factory = common.FromHex("0x36600060003760003660006000f500")
+
+// initCode wraps runtime code in a constructor that returns it.
+func initCode(code []byte) []byte {
+	n := byte(len(code))
+	return append([]byte{0x60, n, 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, n, 0x60, 0x00, 0xf3}, code...)
+}
+
+func TestVerifySignature(t *testing.T) {
+	ctx := context.Background()
+	const msg = "paxeer.app wants you to sign in"
+	owner, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	sign := func(key *ecdsa.PrivateKey) []byte {
+		sig, err := crypto.Sign(accounts.TextHash([]byte(msg)), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig[64] += 27
+		return sig
+	}
+	ownerAddr := crypto.PubkeyToAddress(owner.PublicKey)
+
+	chain := newEVMCaller(t)
+	wallet := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	reverting := common.HexToAddress("0x1000000000000000000000000000000000000002")
+	factory := common.HexToAddress("0x1000000000000000000000000000000000000003")
+	chain.state.SetCode(wallet, ownerWallet(ownerAddr))
+	chain.state.SetCode(reverting, revertingWallet)
+	chain.state.SetCode(factory, create2Factory)
+
+	walletInit := initCode(ownerWallet(ownerAddr))
+	counterfactual := crypto.CreateAddress2(factory, [32]byte{}, crypto.Keccak256(walletInit))
+	wrap := func(sig []byte) []byte {
+		b, err := erc6492Wrapper.Pack(factory, walletInit, sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return append(b, erc6492Suffix...)
+	}
+
+	tests := []struct {
+		name    string
+		address common.Address
+		sig     []byte
+		want    bool
+	}{
+		{"eoa", ownerAddr, sign(owner), true},
+		{"eoa wrong signer", ownerAddr, sign(other), false},
+		{"eip-1271 owner", wallet, sign(owner), true},
+		{"eip-1271 not owner", wallet, sign(other), false},
+		{"eip-1271 reverts", reverting, sign(owner), false},
+		{"eip-6492 counterfactual owner", counterfactual, wrap(sign(owner)), true},
+		{"eip-6492 counterfactual not owner", counterfactual, wrap(sign(other)), false},
+		{"eip-6492 wrong address", common.HexToAddress("0x1000000000000000000000000000000000000004"), wrap(sign(owner)), false},
+		{"eip-6492 already deployed", wallet, wrap(sign(owner)), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := verifySignature(ctx, chain, tt.address.Hex(), hexutil.Encode(tt.sig), msg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tt.want {
+				t.Errorf("verifySignature = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+	if len(chain.state.GetCode(counterfactual)) != 0 {
+		t.Error("counterfactual wallet was left deployed")
+	}
+}
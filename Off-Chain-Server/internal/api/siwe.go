@@ -0,0 +1,211 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// siweMessage is a parsed EIP-4361 (Sign-In with Ethereum) message.
+type siweMessage struct {
+	Scheme         string
+	Domain         string
+	Address        string
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        int64
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime *time.Time
+	NotBefore      *time.Time
+	RequestID      string
+	Resources      []string
+}
+
+const siweHeaderSuffix = " wants you to sign in with your Ethereum account:"
+
+var siweNonce = regexp.MustCompile(`^[A-Za-z0-9]{8,}$`)
+
+// parseSIWE parses the message a wallet signed. It is strict about layout
+// so that the signed text and the validated fields cannot disagree.
+func parseSIWE(msg string) (*siweMessage, error) {
+	lines := strings.Split(strings.ReplaceAll(msg, "\r\n", "\n"), "\n")
+	i := 0
+	next := func() (string, bool) {
+		if i >= len(lines) {
+			return "", false
+		}
+		i++
+		return lines[i-1], true
+	}
+
+	m := &siweMessage{}
+	header, _ := next()
+	if !strings.HasSuffix(header, siweHeaderSuffix) {
+		return nil, errors.New("siwe: missing header")
+	}
+	m.Domain = strings.TrimSuffix(header, siweHeaderSuffix)
+	if scheme, rest, ok := strings.Cut(m.Domain, "://"); ok {
+		m.Scheme, m.Domain = scheme, rest
+	}
+	if m.Domain == "" || strings.ContainsAny(m.Domain, " /") {
+		return nil, errors.New("siwe: invalid domain")
+	}
+
+	addr, _ := next()
+	if !common.IsHexAddress(addr) || common.HexToAddress(addr).Hex() != addr {
+		return nil, errors.New("siwe: address must be EIP-55 checksummed")
+	}
+	m.Address = addr
+
+	// A blank line, then an optional statement followed by another blank line.
+	if l, ok := next(); !ok || l != "" {
+		return nil, errors.New("siwe: expected blank line after address")
+	}
+	if i < len(lines) && !strings.HasPrefix(lines[i], "URI: ") {
+		m.Statement, _ = next()
+		if strings.Contains(m.Statement, "\n") {
+			return nil, errors.New("siwe: invalid statement")
+		}
+		if l, ok := next(); !ok || l != "" {
+			return nil, errors.New("siwe: expected blank line after statement")
+		}
+	}
+
+	field := func(name string, required bool) (string, error) {
+		if i < len(lines) && strings.HasPrefix(lines[i], name+": ") {
+			l, _ := next()
+			return strings.TrimPrefix(l, name+": "), nil
+		}
+		if required {
+			return "", fmt.Errorf("siwe: missing %s", name)
+		}
+		return "", nil
+	}
+	timeField := func(name string, required bool) (*time.Time, error) {
+		v, err := field(name, required)
+		if err != nil || v == "" {
+			return nil, err
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, fmt.Errorf("siwe: invalid %s", name)
+		}
+		return &t, nil
+	}
+
+	var err error
+	if m.URI, err = field("URI", true); err != nil {
+		return nil, err
+	}
+	if u, perr := url.Parse(m.URI); perr != nil || u.Scheme == "" {
+		return nil, errors.New("siwe: invalid URI")
+	}
+	if m.Version, err = field("Version", true); err != nil {
+		return nil, err
+	}
+	if m.Version != "1" {
+		return nil, errors.New("siwe: unsupported version")
+	}
+	chain, err := field("Chain ID", true)
+	if err != nil {
+		return nil, err
+	}
+	if m.ChainID, err = strconv.ParseInt(chain, 10, 64); err != nil || m.ChainID <= 0 {
+		return nil, errors.New("siwe: invalid Chain ID")
+	}
+	if m.Nonce, err = field("Nonce", true); err != nil {
+		return nil, err
+	}
+	if !siweNonce.MatchString(m.Nonce) {
+		return nil, errors.New("siwe: invalid nonce")
+	}
+	issuedAt, err := timeField("Issued At", true)
+	if err != nil {
+		return nil, err
+	}
+	m.IssuedAt = *issuedAt
+	if m.ExpirationTime, err = timeField("Expiration Time", false); err != nil {
+		return nil, err
+	}
+	if m.NotBefore, err = timeField("Not Before", false); err != nil {
+		return nil, err
+	}
+	if m.RequestID, err = field("Request ID", false); err != nil {
+		return nil, err
+	}
+	if i < len(lines) && lines[i] == "Resources:" {
+		next()
+		for i < len(lines) && strings.HasPrefix(lines[i], "- ") {
+			l, _ := next()
+			m.Resources = append(m.Resources, strings.TrimPrefix(l, "- "))
+		}
+	}
+	// Allow a single trailing newline, nothing else.
+	if i < len(lines) && !(i == len(lines)-1 && lines[i] == "") {
+		return nil, fmt.Errorf("siwe: unexpected line %q", lines[i])
+	}
+	return m, nil
+}
+
+// siweRules are the server-side expectations a message must meet.
+type siweRules struct {
+	// Domains allowed in the message; compared case-insensitively.
+	Domains []string
+	ChainID int64
+	// MaxAge bounds how long ago the message may have been issued.
+	MaxAge time.Duration
+	// Skew tolerates clock differences for issued-at and not-before.
+	Skew time.Duration
+}
+
+// validate checks domain, chain and time bounds. The nonce is checked
+// against the nonce store by the caller.
+func (m *siweMessage) validate(rules siweRules, now time.Time) error {
+	allowed := false
+	for _, d := range rules.Domains {
+		if strings.EqualFold(d, m.Domain) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("siwe: domain %q not allowed", m.Domain)
+	}
+	if m.ChainID != rules.ChainID {
+		return fmt.Errorf("siwe: chain ID %d, expected %d", m.ChainID, rules.ChainID)
+	}
+	if m.IssuedAt.After(now.Add(rules.Skew)) {
+		return errors.New("siwe: issued in the future")
+	}
+	if rules.MaxAge > 0 && now.Sub(m.IssuedAt) > rules.MaxAge {
+		return errors.New("siwe: message too old")
+	}
+	if m.ExpirationTime != nil && !now.Before(*m.ExpirationTime) {
+		return errors.New("siwe: message expired")
+	}
+	if m.NotBefore != nil && now.Add(rules.Skew).Before(*m.NotBefore) {
+		return errors.New("siwe: message not yet valid")
+	}
+	return nil
+}
+
+// siweRules builds the validation rules from config. Domains are never taken
+// from the request: with none configured every message is rejected.
+func (s *Server) siweRules() siweRules {
+	rules := siweRules{ChainID: 80000, MaxAge: 10 * time.Minute, Skew: time.Minute}
+	if s.Config == nil {
+		return rules
+	}
+	rules.Domains = s.Config.Auth.Domains
+	rules.ChainID = s.Config.Auth.ChainID
+	rules.MaxAge = time.Duration(s.Config.Auth.MaxMessageAgeSecs) * time.Second
+	return rules
+}
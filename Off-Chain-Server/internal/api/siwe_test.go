@@ -0,0 +1,200 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var siweAddr = common.HexToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed").Hex()
+
+// siweLines joins message lines the way wallets format them.
+func siweLines(lines ...string) string { return strings.Join(lines, "\n") }
+
+func TestParseSIWE(t *testing.T) {
+	header := "app.paxeer.app wants you to sign in with your Ethereum account:"
+	tests := []struct {
+		name string
+		msg  string
+		want *siweMessage
+		err  string
+	}{
+		{
+			name: "with statement",
+			msg: siweLines(header, siweAddr, "", "Sign in to Paxeer.", "",
+				"URI: https://app.paxeer.app", "Version: 1", "Chain ID: 80000", "Nonce: abcd1234EFGH", "Issued At: 2026-10-18T12:00:00Z"),
+			want: &siweMessage{Domain: "app.paxeer.app", Address: siweAddr, Statement: "Sign in to Paxeer.", URI: "https://app.paxeer.app",
+				Version: "1", ChainID: 80000, Nonce: "abcd1234EFGH", IssuedAt: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "without statement",
+			msg: siweLines(header, siweAddr, "",
+				"URI: https://app.paxeer.app", "Version: 1", "Chain ID: 80000", "Nonce: abcd1234EFGH", "Issued At: 2026-10-18T12:00:00Z"),
+			want: &siweMessage{Domain: "app.paxeer.app", Address: siweAddr, URI: "https://app.paxeer.app",
+				Version: "1", ChainID: 80000, Nonce: "abcd1234EFGH", IssuedAt: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "scheme-prefixed domain, optional fields and resources with a trailing newline",
+			msg: siweLines("https://app.paxeer.app:8443 wants you to sign in with your Ethereum account:", siweAddr, "",
+				"URI: https://app.paxeer.app:8443/login", "Version: 1", "Chain ID: 80000", "Nonce: abcd1234EFGH",
+				"Issued At: 2026-10-18T12:00:00.5Z", "Expiration Time: 2026-10-18T12:10:00Z", "Not Before: 2026-10-18T11:59:00Z",
+				"Request ID: req-1", "Resources:", "- ipfs://bafy", "- https://app.paxeer.app/terms", ""),
+			want: &siweMessage{Scheme: "https", Domain: "app.paxeer.app:8443", Address: siweAddr, URI: "https://app.paxeer.app:8443/login",
+				Version: "1", ChainID: 80000, Nonce: "abcd1234EFGH", IssuedAt: time.Date(2026, 10, 18, 12, 0, 0, 5e8, time.UTC),
+				ExpirationTime: ptr(time.Date(2026, 10, 18, 12, 10, 0, 0, time.UTC)), NotBefore: ptr(time.Date(2026, 10, 18, 11, 59, 0, 0, time.UTC)),
+				RequestID: "req-1", Resources: []string{"ipfs://bafy", "https://app.paxeer.app/terms"}},
+		},
+		{
+			name: "CRLF line endings",
+			msg: strings.ReplaceAll(siweLines(header, siweAddr, "",
+				"URI: https://app.paxeer.app", "Version: 1", "Chain ID: 80000", "Nonce: abcd1234EFGH", "Issued At: 2026-10-18T12:00:00Z"), "\n", "\r\n"),
+			want: &siweMessage{Domain: "app.paxeer.app", Address: siweAddr, URI: "https://app.paxeer.app",
+				Version: "1", ChainID: 80000, Nonce: "abcd1234EFGH", IssuedAt: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "non-checksummed address",
+			msg: siweLines(header, strings.ToLower(siweAddr), "",
+				"URI: https://app.paxeer.app", "Version: 1", "Chain ID: 80000", "Nonce: abcd1234EFGH", "Issued At: 2026-10-18T12:00:00Z"),
+			err: "checksummed",
+		},
+		{
+			name: "missing header",
+			msg:  siweLines("app.paxeer.app wants you to sign in", siweAddr),
+			err:  "missing header",
+		},
+		{
+			name: "path in domain",
+			msg:  siweLines("app.paxeer.app/x wants you to sign in with your Ethereum account:", siweAddr),
+			err:  "invalid domain",
+		},
+		{
+			name: "missing Issued At",
+			msg: siweLines(header, siweAddr, "",
+				"URI: https://app.paxeer.app", "Version: 1", "Chain ID: 80000", "Nonce: abcd1234EFGH"),
+			err: "missing Issued At",
+		},
+		{
+			name: "fields out of order",
+			msg: siweLines(header, siweAddr, "",
+				"URI: https://app.paxeer.app", "Chain ID: 80000", "Version: 1", "Nonce: abcd1234EFGH", "Issued At: 2026-10-18T12:00:00Z"),
+			err: "missing Version",
+		},
+		{
+			name: "unsupported version",
+			msg: siweLines(header, siweAddr, "",
+				"URI: https://app.paxeer.app", "Version: 2", "Chain ID: 80000", "Nonce: abcd1234EFGH", "Issued At: 2026-10-18T12:00:00Z"),
+			err: "unsupported version",
+		},
+		{
+			name: "short nonce",
+			msg: siweLines(header, siweAddr, "",
+				"URI: https://app.paxeer.app", "Version: 1", "Chain ID: 80000", "Nonce: abc", "Issued At: 2026-10-18T12:00:00Z"),
+			err: "invalid nonce",
+		},
+		{
+			name: "text after the last field",
+			msg: siweLines(header, siweAddr, "",
+				"URI: https://app.paxeer.app", "Version: 1", "Chain ID: 80000", "Nonce: abcd1234EFGH", "Issued At: 2026-10-18T12:00:00Z", "Extra: 1"),
+			err: "unexpected line",
+		},
+		{
+			name: "two trailing newlines",
+			msg: siweLines(header, siweAddr, "",
+				"URI: https://app.paxeer.app", "Version: 1", "Chain ID: 80000", "Nonce: abcd1234EFGH", "Issued At: 2026-10-18T12:00:00Z", "", ""),
+			err: "unexpected line",
+		},
+		{
+			name: "line after resources",
+			msg: siweLines(header, siweAddr, "",
+				"URI: https://app.paxeer.app", "Version: 1", "Chain ID: 80000", "Nonce: abcd1234EFGH", "Issued At: 2026-10-18T12:00:00Z",
+				"Resources:", "- ipfs://bafy", "Request ID: late"),
+			err: "unexpected line",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := parseSIWE(tt.msg)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got, want := siweString(m), siweString(tt.want); got != want {
+				t.Errorf("got\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// siweString renders every field for comparison, times as instants.
+func siweString(m *siweMessage) string {
+	ts := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return strings.Join([]string{m.Scheme, m.Domain, m.Address, m.Statement, m.URI, m.Version,
+		strconv.FormatInt(m.ChainID, 10), m.Nonce, ts(&m.IssuedAt), ts(m.ExpirationTime), ts(m.NotBefore),
+		m.RequestID, strings.Join(m.Resources, ",")}, "|")
+}
+
+func TestSIWEValidate(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	rules := siweRules{Domains: []string{"app.paxeer.app", "localhost:3000"}, ChainID: 80000, MaxAge: 10 * time.Minute, Skew: time.Minute}
+	valid := func() *siweMessage {
+		return &siweMessage{Domain: "app.paxeer.app", Address: siweAddr, ChainID: 80000, IssuedAt: now.Add(-time.Minute)}
+	}
+	tests := []struct {
+		name  string
+		edit  func(m *siweMessage)
+		rules *siweRules
+		err   string
+	}{
+		{name: "valid"},
+		{name: "domain case-insensitive", edit: func(m *siweMessage) { m.Domain = "APP.Paxeer.app" }},
+		{name: "second allowed domain", edit: func(m *siweMessage) { m.Domain = "localhost:3000" }},
+		{name: "domain not allowed", edit: func(m *siweMessage) { m.Domain = "evil.app" }, err: "not allowed"},
+		{name: "port not allowed", edit: func(m *siweMessage) { m.Domain = "app.paxeer.app:8443" }, err: "not allowed"},
+		{name: "no domains configured", rules: &siweRules{ChainID: 80000, MaxAge: 10 * time.Minute, Skew: time.Minute}, err: "not allowed"},
+		{name: "wrong chain", edit: func(m *siweMessage) { m.ChainID = 1 }, err: "chain ID 1"},
+		{name: "issued within skew", edit: func(m *siweMessage) { m.IssuedAt = now.Add(30 * time.Second) }},
+		{name: "issued in the future", edit: func(m *siweMessage) { m.IssuedAt = now.Add(2 * time.Minute) }, err: "future"},
+		{name: "too old", edit: func(m *siweMessage) { m.IssuedAt = now.Add(-11 * time.Minute) }, err: "too old"},
+		{name: "expired", edit: func(m *siweMessage) { m.ExpirationTime = ptr(now) }, err: "expired"},
+		{name: "not yet expired", edit: func(m *siweMessage) { m.ExpirationTime = ptr(now.Add(time.Second)) }},
+		{name: "not before within skew", edit: func(m *siweMessage) { m.NotBefore = ptr(now.Add(45 * time.Second)) }},
+		{name: "not before beyond skew", edit: func(m *siweMessage) { m.NotBefore = ptr(now.Add(2 * time.Minute)) }, err: "not yet valid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := valid()
+			if tt.edit != nil {
+				tt.edit(m)
+			}
+			r := rules
+			if tt.rules != nil {
+				r = *tt.rules
+			}
+			err := m.validate(r, now)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package api
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/paxeer/offchain-server/internal/indexer"
+)
+
+const (
+	defaultStatsWindow = "24h"
+	maxStatsWindow     = 30 * 86400
+	// statsTTL bounds staleness from the sliding window when a pool is quiet.
+	statsTTL = 30 * time.Second
+)
+
+// poolStats summarises a pool's trading over a trailing window. Amounts are
+// USDC wei; prices are X18.
+type poolStats struct {
+	Pool          string    `json:"pool"`
+	Window        string    `json:"window"`
+	WindowStart   time.Time `json:"windowStart"`
+	VolumeUSDC    string    `json:"volumeUSDC"`
+	Buys          int64     `json:"buys"`
+	Sells         int64     `json:"sells"`
+	Trades        int64     `json:"trades"`
+	UniqueTraders int64     `json:"uniqueTraders"`
+	OpenX18       *string   `json:"openX18"`
+	HighX18       *string   `json:"highX18"`
+	LowX18        *string   `json:"lowX18"`
+	PriceX18      *string   `json:"priceX18"`
+	ChangePct     *string   `json:"changePct"`
+	MarketCapUSDC *string   `json:"marketCapUSDC"`
+	FDVUSDC       *string   `json:"fdvUSDC"`
+	LiquidityUSDC string    `json:"liquidityUSDC"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+type statsEntry struct {
+	stats *poolStats
+	at    time.Time
+}
+
+// cachedStatsWindows are the window lengths worth caching, with the label
+// their stats carry whichever spelling was requested (?window=1d is "24h").
+// Arbitrary ?window= durations are computed on every request so clients
+// cannot grow the cache by varying them.
+var cachedStatsWindows = map[int64]string{300: "5m", 3600: "1h", 86400: "24h", 7 * 86400: "7d"}
+
+// statsCache holds computed stats per pool and window length. Entries are
+// dropped when the indexer reports activity on the pool, and expire after
+// statsTTL regardless.
+type statsCache struct {
+	mu        sync.Mutex
+	entries   map[string]map[int64]statsEntry
+	lastSweep time.Time
+}
+
+func newStatsCache() *statsCache {
+	return &statsCache{entries: make(map[string]map[int64]statsEntry)}
+}
+
+func (c *statsCache) get(pool string, secs int64) *poolStats {
+	if _, ok := cachedStatsWindows[secs]; !ok {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[strings.ToLower(pool)][secs]
+	if !ok || time.Since(e.at) > statsTTL {
+		return nil
+	}
+	return e.stats
+}
+
+func (c *statsCache) put(pool string, secs int64, st *poolStats) {
+	if _, ok := cachedStatsWindows[secs]; !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if now.Sub(c.lastSweep) > statsTTL {
+		c.sweep(now)
+	}
+	key := strings.ToLower(pool)
+	if c.entries[key] == nil {
+		c.entries[key] = make(map[int64]statsEntry)
+	}
+	c.entries[key][secs] = statsEntry{stats: st, at: now}
+}
+
+// sweep drops expired entries; c.mu must be held.
+func (c *statsCache) sweep(now time.Time) {
+	for pool, windows := range c.entries {
+		for secs, e := range windows {
+			if now.Sub(e.at) > statsTTL {
+				delete(windows, secs)
+			}
+		}
+		if len(windows) == 0 {
+			delete(c.entries, pool)
+		}
+	}
+	c.lastSweep = now
+}
+
+// invalidate drops a pool's entries, or everything when pool is empty.
+func (c *statsCache) invalidate(pool string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pool == "" {
+		c.entries = make(map[string]map[int64]statsEntry)
+		return
+	}
+	delete(c.entries, strings.ToLower(pool))
+}
+
+// parseWindow accepts a bucket shorthand (5m, 1h, 1d) or a Go duration and
+// returns its length in seconds.
+func parseWindow(v string) (int64, bool) {
+	if secs, ok := bucketSeconds(v); ok {
+		return secs, secs > 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < time.Second {
+		return 0, false
+	}
+	return int64(d.Seconds()), true
+}
+
+// pctChange formats (to-from)/from as a percentage with 4 decimals.
+func pctChange(from, to string) *string {
+	f, ok1 := new(big.Rat).SetString(from)
+	t, ok2 := new(big.Rat).SetString(to)
+	if !ok1 || !ok2 || f.Sign() == 0 {
+		return nil
+	}
+	r := new(big.Rat).Sub(t, f)
+	r.Mul(r, big.NewRat(100, 1)).Quo(r, f)
+	s := r.FloatString(4)
+	return &s
+}
+
+// poolStatsQuery computes the window stats of the pools in $1 since $2.
+// The open is the price in effect when the window starts, or the first
+// price inside it for pools younger than the window.
+const poolStatsQuery = `
+	SELECT p.pool_address, p.spot_x18, pv.market_cap, pv.fdv, pv.tvl,
+		COALESCE(sw.volume, 0), sw.buys, sw.sells, sw.traders, pu.high, pu.low,
+		COALESCE(op.before_start, op.first_inside)
+	FROM pools p
+	JOIN pool_valuations pv ON pv.pool_address = p.pool_address
+	LEFT JOIN LATERAL (
+		SELECT SUM(CASE WHEN s.usdc_to_token THEN s.amount_in ELSE s.amount_out END) AS volume,
+			COUNT(*) FILTER (WHERE s.usdc_to_token) AS buys, COUNT(*) FILTER (WHERE NOT s.usdc_to_token) AS sells,
+			COUNT(DISTINCT LOWER(s.recipient)) AS traders
+		FROM swaps s WHERE s.pool_address = p.pool_address AND s.block_time >= $2
+	) sw ON TRUE
+	LEFT JOIN LATERAL (
+		SELECT MAX(u.price_x18) AS high, MIN(u.price_x18) AS low
+		FROM price_updates u WHERE u.pool_address = p.pool_address AND u.block_time >= $2
+	) pu ON TRUE
+	LEFT JOIN LATERAL (
+		SELECT
+			(SELECT u.price_x18 FROM price_updates u WHERE u.pool_address = p.pool_address AND u.block_time < $2 ORDER BY u.block_number DESC, u.log_index DESC LIMIT 1) AS before_start,
+			(SELECT u.price_x18 FROM price_updates u WHERE u.pool_address = p.pool_address AND u.block_time >= $2 ORDER BY u.block_number ASC, u.log_index ASC LIMIT 1) AS first_inside
+	) op ON TRUE
+	WHERE p.pool_address = ANY($1)`
+
+// poolStatsBatch returns stats for the trailing window of each known pool,
+// serving cache hits and computing all misses in one query.
+func (s *Server) poolStatsBatch(ctx context.Context, pools []string, window string, secs int64) (map[string]*poolStats, error) {
+	if label, ok := cachedStatsWindows[secs]; ok {
+		window = label
+	}
+	out := make(map[string]*poolStats, len(pools))
+	var missing []string
+	for _, pool := range pools {
+		if st := s.stats.get(pool, secs); st != nil {
+			out[pool] = st
+		} else {
+			missing = append(missing, pool)
+		}
+	}
+	if len(missing) == 0 {
+		return out, nil
+	}
+	now := time.Now().UTC()
+	start := now.Add(-time.Duration(secs) * time.Second)
+	rows, err := s.DB.Query(ctx, poolStatsQuery, missing, start)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		st := &poolStats{Window: window, WindowStart: start, UpdatedAt: now}
+		var high, low *string
+		if err := rows.Scan(&st.Pool, &st.PriceX18, &st.MarketCapUSDC, &st.FDVUSDC, &st.LiquidityUSDC,
+			&st.VolumeUSDC, &st.Buys, &st.Sells, &st.UniqueTraders, &high, &low, &st.OpenX18); err != nil {
+			return nil, err
+		}
+		st.Trades = st.Buys + st.Sells
+		st.HighX18, st.LowX18 = high, low
+		if st.OpenX18 != nil {
+			if st.HighX18 == nil || cmpNumeric(*st.OpenX18, *st.HighX18) > 0 { st.HighX18 = st.OpenX18 }
+			if st.LowX18 == nil || cmpNumeric(*st.OpenX18, *st.LowX18) < 0 { st.LowX18 = st.OpenX18 }
+			if st.PriceX18 != nil { st.ChangePct = pctChange(*st.OpenX18, *st.PriceX18) }
+		}
+		s.stats.put(st.Pool, secs, st)
+		out[st.Pool] = st
+	}
+	return out, rows.Err()
+}
+
+// poolStats returns cached stats for the trailing window, computing them on a miss.
+// It returns pgx.ErrNoRows for unknown pools.
+func (s *Server) poolStats(ctx context.Context, pool, window string, secs int64) (*poolStats, error) {
+	m, err := s.poolStatsBatch(ctx, []string{pool}, window, secs)
+	if err != nil {
+		return nil, err
+	}
+	st := m[pool]
+	if st == nil {
+		return nil, pgx.ErrNoRows
+	}
+	return st, nil
+}
+
+// GET /pools/{pool}/stats?window=24h
+func (s *Server) handlePoolStats(w http.ResponseWriter, r *http.Request) {
+	pool := extractBetween(r.URL.Path, "/pools/", "/stats")
+	window := r.URL.Query().Get("window")
+	if window == "" { window = defaultStatsWindow }
+	secs, ok := parseWindow(window)
+	if !ok || secs > maxStatsWindow {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid window"})
+		return
+	}
+	st, err := s.poolStats(r.Context(), pool, window, secs)
+	if err == pgx.ErrNoRows {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "pool not found"})
+		return
+	}
+	if err != nil { writeErr(w, err); return }
+	writeJSON(w, http.StatusOK, st)
+}
+
+// ListenPoolActivity invalidates cached stats when the indexer signals new
+// swaps or prices on indexer.PoolActivityChannel. It reconnects until ctx is done.
+func (s *Server) ListenPoolActivity(ctx context.Context) {
+	for ctx.Err() == nil {
+		err := s.listenPoolActivity(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("[warn] pool activity listener: %v", err)
+		// Notifications may have been missed while disconnected.
+		s.stats.invalidate("")
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (s *Server) listenPoolActivity(ctx context.Context) error {
+	pc, err := s.DB.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// LISTEN is session state, so take the connection out of the pool for good.
+	conn := pc.Hijack()
+	defer conn.Close(context.Background())
+	if _, err := conn.Exec(ctx, "LISTEN "+indexer.PoolActivityChannel); err != nil {
+		return err
+	}
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		s.stats.invalidate(n.Payload)
+	}
+}
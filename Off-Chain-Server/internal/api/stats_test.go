@@ -0,0 +1,38 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatsCacheWindows(t *testing.T) {
+	c := newStatsCache()
+	st := &poolStats{Window: "24h"}
+	c.put("0xPool", 86400, st)
+	if got := c.get("0xpool", 86400); got != st {
+		t.Errorf("get 24h = %v, want cached entry", got)
+	}
+	// Arbitrary windows are never cached.
+	c.put("0xPool", 5400, &poolStats{Window: "90m"})
+	if got := c.get("0xPool", 5400); got != nil {
+		t.Errorf("get 90m = %v, want nil", got)
+	}
+	c.invalidate("0xPOOL")
+	if got := c.get("0xPool", 86400); got != nil {
+		t.Errorf("get after invalidate = %v, want nil", got)
+	}
+}
+
+func TestStatsCacheSweep(t *testing.T) {
+	c := newStatsCache()
+	c.put("0xa", 300, &poolStats{})
+	c.put("0xb", 300, &poolStats{})
+	c.entries["0xa"][300] = statsEntry{stats: &poolStats{}, at: time.Now().Add(-2 * statsTTL)}
+	c.sweep(time.Now())
+	if _, ok := c.entries["0xa"]; ok {
+		t.Error("expired pool was not swept")
+	}
+	if _, ok := c.entries["0xb"][300]; !ok {
+		t.Error("fresh entry was swept")
+	}
+}
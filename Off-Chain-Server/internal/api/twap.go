@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type oracleObservation struct {
+	PriceCumulative string `json:"priceCumulative"`
+	OracleTimestamp int64  `json:"oracleTimestamp"`
+	Block           int64  `json:"blockNumber"`
+	Tx              string `json:"txHash"`
+	LogIndex        int    `json:"logIndex"`
+}
+
+// twapPoint is the price cumulative evaluated at one end of a TWAP window.
+type twapPoint struct {
+	Timestamp       int64  `json:"timestamp"`
+	PriceCumulative string `json:"priceCumulative"`
+	// Method is "exact", "interpolated" (between two observations) or
+	// "extrapolated" (past the last observation, using the spot price).
+	Method       string              `json:"method"`
+	Observations []oracleObservation `json:"observations"`
+	SpotX18      string              `json:"spotX18,omitempty"`
+}
+
+func (s *Server) oracleObservation(ctx context.Context, query string, pool string, ts int64) (*oracleObservation, error) {
+	var o oracleObservation
+	err := s.DB.QueryRow(ctx, query, pool, ts).Scan(&o.PriceCumulative, &o.OracleTimestamp, &o.Block, &o.Tx, &o.LogIndex)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// cumulativeAt evaluates the oracle price cumulative at ts. Between two
+// observations it interpolates linearly, which matches the oracle's own
+// accounting; after the last one it extends with the spot price.
+// It returns nil when ts precedes the first observation.
+func (s *Server) cumulativeAt(ctx context.Context, pool string, ts int64, spot *big.Int) (*twapPoint, error) {
+	before, err := s.oracleObservation(ctx, `SELECT price_cumulative, oracle_timestamp, block_number, tx_hash, log_index FROM oracle_updates WHERE pool_address = $1 AND oracle_timestamp <= $2 ORDER BY oracle_timestamp DESC, block_number DESC, log_index DESC LIMIT 1`, pool, ts)
+	if err != nil || before == nil {
+		return nil, err
+	}
+	cumA, _ := new(big.Int).SetString(before.PriceCumulative, 10)
+	pt := &twapPoint{Timestamp: ts, Observations: []oracleObservation{*before}}
+	if before.OracleTimestamp == ts {
+		pt.Method = "exact"
+		pt.PriceCumulative = cumA.String()
+		return pt, nil
+	}
+	after, err := s.oracleObservation(ctx, `SELECT price_cumulative, oracle_timestamp, block_number, tx_hash, log_index FROM oracle_updates WHERE pool_address = $1 AND oracle_timestamp > $2 ORDER BY oracle_timestamp ASC, block_number ASC, log_index ASC LIMIT 1`, pool, ts)
+	if err != nil {
+		return nil, err
+	}
+	elapsed := big.NewInt(ts - before.OracleTimestamp)
+	if after != nil {
+		cumB, _ := new(big.Int).SetString(after.PriceCumulative, 10)
+		delta := new(big.Int).Sub(cumB, cumA)
+		delta.Mul(delta, elapsed).Quo(delta, big.NewInt(after.OracleTimestamp-before.OracleTimestamp))
+		pt.Method = "interpolated"
+		pt.PriceCumulative = delta.Add(delta, cumA).String()
+		pt.Observations = append(pt.Observations, *after)
+		return pt, nil
+	}
+	ext := new(big.Int).Mul(spot, elapsed)
+	pt.Method = "extrapolated"
+	pt.PriceCumulative = ext.Add(ext, cumA).String()
+	pt.SpotX18 = spot.String()
+	return pt, nil
+}
+
+// GET /pools/{pool}/twap?window=1h&at=
+// Time-weighted average price over [at-window, at] from LaunchPoolOracle
+// cumulatives. "at" defaults to now; both ends are returned with the
+// observations used so the number can be reproduced.
+func (s *Server) handleTWAP(w http.ResponseWriter, r *http.Request) {
+	pool := extractBetween(r.URL.Path, "/pools/", "/twap")
+	q := r.URL.Query()
+	window := q.Get("window")
+	if window == "" {
+		window = "1h"
+	}
+	secs, ok := parseWindow(window)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid window"})
+		return
+	}
+	end := time.Now().UTC()
+	if v := q.Get("at"); v != "" {
+		t, ok := parseTimeParam(v)
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid at"})
+			return
+		}
+		end = t
+	}
+
+	var spotStr *string
+	if err := s.DB.QueryRow(r.Context(), `SELECT spot_x18 FROM pools WHERE pool_address = $1`, pool).Scan(&spotStr); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "pool not found"})
+		return
+	}
+	spot := new(big.Int)
+	if spotStr != nil {
+		if v, ok := parseBig(*spotStr); ok {
+			spot = v
+		}
+	}
+
+	endTs := end.Unix()
+	startTs := endTs - secs
+	endPt, err := s.cumulativeAt(r.Context(), pool, endTs, spot)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	if endPt == nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "no oracle observations before window end"})
+		return
+	}
+	startPt, err := s.cumulativeAt(r.Context(), pool, startTs, spot)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	clamped := false
+	if startPt == nil {
+		// Window starts before the pool existed: begin at the first observation.
+		first, err := s.oracleObservation(r.Context(), `SELECT price_cumulative, oracle_timestamp, block_number, tx_hash, log_index FROM oracle_updates WHERE pool_address = $1 AND oracle_timestamp >= $2 ORDER BY oracle_timestamp ASC, block_number ASC, log_index ASC LIMIT 1`, pool, startTs)
+		if err != nil {
+			writeErr(w, err)
+			return
+		}
+		if first == nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "no oracle observations in window"})
+			return
+		}
+		startPt = &twapPoint{Timestamp: first.OracleTimestamp, PriceCumulative: first.PriceCumulative, Method: "exact", Observations: []oracleObservation{*first}}
+		clamped = true
+	}
+	if endPt.Timestamp <= startPt.Timestamp {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "window has no elapsed oracle time"})
+		return
+	}
+
+	cumStart, _ := new(big.Int).SetString(startPt.PriceCumulative, 10)
+	twap, _ := new(big.Int).SetString(endPt.PriceCumulative, 10)
+	twap.Sub(twap, cumStart).Quo(twap, big.NewInt(endPt.Timestamp-startPt.Timestamp))
+	writeJSON(w, http.StatusOK, map[string]any{
+		"pool":          pool,
+		"twapX18":       twap.String(),
+		"windowStart":   time.Unix(startPt.Timestamp, 0).UTC(),
+		"windowEnd":     time.Unix(endPt.Timestamp, 0).UTC(),
+		"windowClamped": clamped,
+		"start":         startPt,
+		"end":           endPt,
+	})
+}
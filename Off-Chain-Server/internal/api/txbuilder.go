@@ -0,0 +1,243 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// unsignedTx is a ready-to-sign call payload for wallets.
+type unsignedTx struct {
+	To          string `json:"to"`
+	Data        string `json:"data"`
+	Value       string `json:"value"`
+	Description string `json:"description"`
+}
+
+// txRequest is the body accepted by every /tx/* builder. Amount fields that
+// do not apply to an action are ignored.
+type txRequest struct {
+	Token       string `json:"token"`
+	AmountIn    string `json:"amountIn"`
+	AmountUSDC  string `json:"amountUSDC"`
+	AmountToken string `json:"amountToken"`
+	LPTokens    string `json:"lpTokens"`
+	MinOut      string `json:"minOut"`
+	SlippageBps *int64 `json:"slippageBps"`
+	Recipient   string `json:"recipient"`
+	From        string `json:"from"`
+}
+
+func encodeCall(contractABI abi.ABI, to common.Address, desc, method string, args ...any) (unsignedTx, error) {
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return unsignedTx{}, err
+	}
+	return unsignedTx{To: to.Hex(), Data: hexutil.Encode(data), Value: "0x0", Description: desc}, nil
+}
+
+// allowance reads ERC20 allowance(owner, spender). ok is false when no RPC is configured.
+func (s *Server) allowance(ctx context.Context, erc20, owner, spender common.Address) (*big.Int, bool, error) {
+	if s.RPC == nil {
+		return nil, false, nil
+	}
+	data, err := s.ABIs.ERC20.Pack("allowance", owner, spender)
+	if err != nil {
+		return nil, false, err
+	}
+	out, err := s.RPC.CallContract(ctx, ethereum.CallMsg{To: &erc20, Data: data}, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	vals, err := s.ABIs.ERC20.Unpack("allowance", out)
+	if err != nil {
+		return nil, false, err
+	}
+	v, _ := vals[0].(*big.Int)
+	return v, v != nil, nil
+}
+
+// approvalIfNeeded returns an approve(router, amount) call when the owner's
+// allowance is below amount. Without RPC the approval is always included.
+func (s *Server) approvalIfNeeded(ctx context.Context, erc20, owner, router common.Address, amount *big.Int, desc string) ([]unsignedTx, error) {
+	current, ok, err := s.allowance(ctx, erc20, owner, router)
+	if err != nil {
+		return nil, err
+	}
+	if ok && current.Cmp(amount) >= 0 {
+		return nil, nil
+	}
+	tx, err := encodeCall(s.ABIs.ERC20, erc20, desc, "approve", router, amount)
+	if err != nil {
+		return nil, err
+	}
+	return []unsignedTx{tx}, nil
+}
+
+// txAddrs are the addresses a router call involves.
+type txAddrs struct {
+	Router, USDC, Token, Pool, From, Recipient common.Address
+}
+
+// routerTxs encodes the LaunchpadRouter call for action, preceded by the
+// approvals From still needs. amounts are amountIn and minOut for buy/sell,
+// amountUSDC and amountToken for add-liquidity, and lpTokens for
+// remove-liquidity.
+func (s *Server) routerTxs(ctx context.Context, action string, a txAddrs, amounts ...*big.Int) ([]unsignedTx, error) {
+	var (
+		txs  []unsignedTx
+		call unsignedTx
+		err  error
+	)
+	switch action {
+	case "buy":
+		txs, err = s.approvalIfNeeded(ctx, a.USDC, a.From, a.Router, amounts[0], "approve USDC")
+		if err == nil {
+			call, err = encodeCall(s.ABIs.Router, a.Router, "swap USDC for tokens", "swapExactUSDCForTokens", a.Token, amounts[0], amounts[1], a.Recipient)
+		}
+	case "sell":
+		txs, err = s.approvalIfNeeded(ctx, a.Token, a.From, a.Router, amounts[0], "approve token")
+		if err == nil {
+			call, err = encodeCall(s.ABIs.Router, a.Router, "swap tokens for USDC", "swapExactTokensForUSDC", a.Token, amounts[0], amounts[1], a.Recipient)
+		}
+	case "add-liquidity":
+		txs, err = s.approvalIfNeeded(ctx, a.USDC, a.From, a.Router, amounts[0], "approve USDC")
+		if err == nil {
+			var more []unsignedTx
+			more, err = s.approvalIfNeeded(ctx, a.Token, a.From, a.Router, amounts[1], "approve token")
+			txs = append(txs, more...)
+		}
+		if err == nil {
+			call, err = encodeCall(s.ABIs.Router, a.Router, "add liquidity", "addLiquidity", a.Token, amounts[0], amounts[1], a.Recipient)
+		}
+	case "remove-liquidity":
+		txs, err = s.approvalIfNeeded(ctx, a.Pool, a.From, a.Router, amounts[0], "approve LP token")
+		if err == nil {
+			call, err = encodeCall(s.ABIs.Router, a.Router, "remove liquidity", "removeLiquidity", a.Token, amounts[0], a.Recipient)
+		}
+	default:
+		return nil, fmt.Errorf("unknown action %q", action)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(txs, call), nil
+}
+
+func (s *Server) poolForToken(ctx context.Context, token common.Address) (string, error) {
+	var pool string
+	err := s.DB.QueryRow(ctx, `SELECT pool_address FROM pools WHERE LOWER(token_address) = LOWER($1)`, token.Hex()).Scan(&pool)
+	return pool, err
+}
+
+// POST /tx/{buy|sell|add-liquidity|remove-liquidity}
+// Builds unsigned LaunchpadRouter calls, preceded by any ERC20 approvals the
+// sender still needs. "from" defaults to "recipient".
+func (s *Server) handleBuildTx(w http.ResponseWriter, r *http.Request) {
+	action := strings.TrimPrefix(r.URL.Path, "/tx/")
+	if s.Config == nil || !common.IsHexAddress(s.Config.Contracts.Router) || !common.IsHexAddress(s.Config.Contracts.USDC) {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": "router or usdc address not configured"})
+		return
+	}
+	router := common.HexToAddress(s.Config.Contracts.Router)
+	usdc := common.HexToAddress(s.Config.Contracts.USDC)
+
+	var in txRequest
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil { writeErr(w, err); return }
+	if !common.IsHexAddress(in.Token) {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid token"})
+		return
+	}
+	if !common.IsHexAddress(in.Recipient) {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid recipient"})
+		return
+	}
+	if in.From == "" { in.From = in.Recipient }
+	if !common.IsHexAddress(in.From) {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid from"})
+		return
+	}
+	slippage := int64(defaultSlippageBps)
+	if in.SlippageBps != nil {
+		if *in.SlippageBps < 0 || *in.SlippageBps > bpsDenom {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid slippageBps"})
+			return
+		}
+		slippage = *in.SlippageBps
+	}
+	token := common.HexToAddress(in.Token)
+	recipient := common.HexToAddress(in.Recipient)
+	from := common.HexToAddress(in.From)
+	pool, err := s.poolForToken(r.Context(), token)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "pool not found"})
+		return
+	}
+
+	var (
+		amounts []*big.Int
+		extra   = map[string]any{}
+		badArg  = func(name string) { writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid " + name}) }
+	)
+	switch action {
+	case "buy", "sell":
+		amountIn, ok := parseBig(in.AmountIn)
+		if !ok || amountIn.Sign() == 0 { badArg("amountIn"); return }
+		minOut, ok := parseBig(in.MinOut)
+		if !ok {
+			res, err := s.loadPoolReserves(r.Context(), pool)
+			if err != nil {
+				if errors.Is(err, errVirtualUnknown) {
+					writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
+					return
+				}
+				writeErr(w, err)
+				return
+			}
+			var quote swapQuote
+			if action == "buy" {
+				quote, err = res.quoteBuy(amountIn)
+			} else {
+				quote, err = res.quoteSell(amountIn)
+			}
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+				return
+			}
+			minOut = applySlippage(quote.AmountOut, slippage)
+			extra["expectedOut"] = quote.AmountOut.String()
+			extra["slippageBps"] = slippage
+		}
+		extra["minOut"] = minOut.String()
+		amounts = []*big.Int{amountIn, minOut}
+	case "add-liquidity":
+		amountUSDC, ok := parseBig(in.AmountUSDC)
+		if !ok || amountUSDC.Sign() == 0 { badArg("amountUSDC"); return }
+		amountToken, ok := parseBig(in.AmountToken)
+		if !ok || amountToken.Sign() == 0 { badArg("amountToken"); return }
+		amounts = []*big.Int{amountUSDC, amountToken}
+	case "remove-liquidity":
+		lp, ok := parseBig(in.LPTokens)
+		if !ok || lp.Sign() == 0 { badArg("lpTokens"); return }
+		amounts = []*big.Int{lp}
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "not found"})
+		return
+	}
+	txs, err := s.routerTxs(r.Context(), action, txAddrs{Router: router, USDC: usdc, Token: token, Pool: common.HexToAddress(pool), From: from, Recipient: recipient}, amounts...)
+	if err != nil { writeErr(w, err); return }
+
+	extra["pool"] = pool
+	extra["allowanceChecked"] = s.RPC != nil
+	extra["transactions"] = txs
+	writeJSON(w, http.StatusOK, extra)
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/paxeer/offchain-server/internal/indexer"
+)
+
+// allowanceCaller answers every ERC20 allowance call with the same amount.
+type allowanceCaller struct {
+	amount *big.Int
+}
+
+func (c allowanceCaller) CodeAt(context.Context, common.Address, *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (c allowanceCaller) CallContract(context.Context, ethereum.CallMsg, *big.Int) ([]byte, error) {
+	return common.LeftPadBytes(c.amount.Bytes(), 32), nil
+}
+
+// decodeCall unpacks tx's calldata with contractABI, returning the method name and arguments.
+func decodeCall(t *testing.T, contractABI abi.ABI, tx unsignedTx) (string, []any) {
+	t.Helper()
+	data, err := hexutil.Decode(tx.Data)
+	if err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	m, err := contractABI.MethodById(data[:4])
+	if err != nil {
+		t.Fatalf("unknown selector %x: %v", data[:4], err)
+	}
+	args, err := m.Inputs.Unpack(data[4:])
+	if err != nil {
+		t.Fatalf("unpack %s: %v", m.Name, err)
+	}
+	return m.Name, args
+}
+
+func TestRouterTxsDecode(t *testing.T) {
+	abis, err := indexer.LoadABIs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{ABIs: abis}
+	a := txAddrs{
+		Router:    common.HexToAddress("0x00000000000000000000000000000000000000a1"),
+		USDC:      common.HexToAddress("0x00000000000000000000000000000000000000a2"),
+		Token:     common.HexToAddress("0x00000000000000000000000000000000000000a3"),
+		Pool:      common.HexToAddress("0x00000000000000000000000000000000000000a4"),
+		From:      common.HexToAddress("0x00000000000000000000000000000000000000a5"),
+		Recipient: common.HexToAddress("0x00000000000000000000000000000000000000a6"),
+	}
+	res := poolReserves{
+		VirtualUSDC: bigStr(t, "10000000000000000000000"), VirtualToken: big.NewInt(0),
+		RealUSDC: bigStr(t, "2500000000000000000000"), RealToken: bigStr(t, "800000000000000000000000000"),
+	}
+	buyIn := bigStr(t, "1234567890123456789")
+	buy, err := res.quoteBuy(buyIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sellIn := bigStr(t, "5000000000000000000000000")
+	sell, err := res.quoteSell(sellIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// minOut is the quote less 50 bps, rounded down (see TestQuoteMatchesLaunchPool).
+	buyMin := applySlippage(buy.AmountOut, defaultSlippageBps)
+	if want := bigStr(t, "77823501001712761641149"); buyMin.Cmp(want) != 0 {
+		t.Fatalf("buy minOut = %s, want %s", buyMin, want)
+	}
+	sellMin := applySlippage(sell.AmountOut, defaultSlippageBps)
+	lp := big.NewInt(42)
+
+	type approval struct {
+		token  common.Address
+		amount *big.Int
+	}
+	tests := []struct {
+		action    string
+		amounts   []*big.Int
+		approvals []approval
+		method    string
+		args      []any
+	}{
+		{
+			action: "buy", amounts: []*big.Int{buyIn, buyMin},
+			approvals: []approval{{a.USDC, buyIn}},
+			method:    "swapExactUSDCForTokens", args: []any{a.Token, buyIn, buyMin, a.Recipient},
+		},
+		{
+			action: "sell", amounts: []*big.Int{sellIn, sellMin},
+			approvals: []approval{{a.Token, sellIn}},
+			method:    "swapExactTokensForUSDC", args: []any{a.Token, sellIn, sellMin, a.Recipient},
+		},
+		{
+			action: "add-liquidity", amounts: []*big.Int{buyIn, sellIn},
+			approvals: []approval{{a.USDC, buyIn}, {a.Token, sellIn}},
+			method:    "addLiquidity", args: []any{a.Token, buyIn, sellIn, a.Recipient},
+		},
+		{
+			action: "remove-liquidity", amounts: []*big.Int{lp},
+			approvals: []approval{{a.Pool, lp}},
+			method:    "removeLiquidity", args: []any{a.Token, lp, a.Recipient},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			txs, err := s.routerTxs(context.Background(), tt.action, a, tt.amounts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(txs) != len(tt.approvals)+1 {
+				t.Fatalf("got %d transactions, want %d", len(txs), len(tt.approvals)+1)
+			}
+			for i, ap := range tt.approvals {
+				if txs[i].To != ap.token.Hex() {
+					t.Errorf("approval %d to %s, want %s", i, txs[i].To, ap.token.Hex())
+				}
+				name, args := decodeCall(t, abis.ERC20, txs[i])
+				if name != "approve" || args[0].(common.Address) != a.Router || args[1].(*big.Int).Cmp(ap.amount) != 0 {
+					t.Errorf("approval %d = %s%v, want approve(router %s, %s)", i, name, args, a.Router.Hex(), ap.amount)
+				}
+			}
+			call := txs[len(txs)-1]
+			if call.To != a.Router.Hex() || call.Value != "0x0" {
+				t.Errorf("call to %s value %s, want router %s value 0x0", call.To, call.Value, a.Router.Hex())
+			}
+			name, args := decodeCall(t, abis.Router, call)
+			if name != tt.method {
+				t.Fatalf("method = %s, want %s", name, tt.method)
+			}
+			for i, want := range tt.args {
+				switch w := want.(type) {
+				case *big.Int:
+					if args[i].(*big.Int).Cmp(w) != 0 {
+						t.Errorf("arg %d = %s, want %s", i, args[i], w)
+					}
+				default:
+					if args[i] != want {
+						t.Errorf("arg %d = %v, want %v", i, args[i], want)
+					}
+				}
+			}
+		})
+	}
+
+	// With enough allowance over RPC only the router call is returned.
+	s.RPC = allowanceCaller{amount: buyIn}
+	txs, err := s.routerTxs(context.Background(), "buy", a, buyIn, buyMin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 1 {
+		t.Fatalf("got %d transactions with sufficient allowance, want 1", len(txs))
+	}
+}
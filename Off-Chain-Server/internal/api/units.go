@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// defaultTokenDecimals applies to pools whose token decimals have not been read yet.
+const defaultTokenDecimals = 18
+
+// formatUnits renders raw / 10^decimals as an exact decimal string with
+// trailing zeros trimmed. Negative decimals scale up.
+func formatUnits(raw string, decimals int) string {
+	v, ok := new(big.Int).SetString(raw, 10)
+	if !ok {
+		return ""
+	}
+	if decimals <= 0 {
+		return v.Mul(v, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-decimals)), nil)).String()
+	}
+	sign := ""
+	if v.Sign() < 0 {
+		sign = "-"
+		v.Neg(v)
+	}
+	digits := v.String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole, frac := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if frac == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + frac
+}
+
+func formatUnitsPtr(raw *string, decimals int) *string {
+	if raw == nil {
+		return nil
+	}
+	s := formatUnits(*raw, decimals)
+	return &s
+}
+
+// poolDecimals holds the decimals needed to format a pool's amounts. Prices
+// are X18 ratios of raw USDC to raw token units, so a human price is
+// priceX18 / 10^(18 + USDCDecimals - TokenDecimals).
+type poolDecimals struct {
+	TokenDecimals int `json:"tokenDecimals"`
+	USDCDecimals  int `json:"usdcDecimals"`
+}
+
+func (d poolDecimals) price(x18 string) string {
+	return formatUnits(x18, 18+d.USDCDecimals-d.TokenDecimals)
+}
+func (d poolDecimals) usdc(raw string) string  { return formatUnits(raw, d.USDCDecimals) }
+func (d poolDecimals) token(raw string) string { return formatUnits(raw, d.TokenDecimals) }
+func (d poolDecimals) pricePtr(x18 *string) *string {
+	return formatUnitsPtr(x18, 18+d.USDCDecimals-d.TokenDecimals)
+}
+
+func (s *Server) usdcDecimals() int {
+	if s.Config == nil || s.Config.Contracts.USDCDecimals == 0 {
+		return 18
+	}
+	return s.Config.Contracts.USDCDecimals
+}
+
+// poolDecimals reads the pool's token decimals, falling back to the default
+// for unknown pools or before the indexer has read them.
+func (s *Server) poolDecimals(ctx context.Context, pool string) poolDecimals {
+	d := poolDecimals{TokenDecimals: defaultTokenDecimals, USDCDecimals: s.usdcDecimals()}
+	var dec *int16
+	if err := s.DB.QueryRow(ctx, `SELECT token_decimals FROM pools WHERE pool_address = $1`, pool).Scan(&dec); err == nil && dec != nil {
+		d.TokenDecimals = int(*dec)
+	}
+	return d
+}
+
+// CheckUSDCDecimals compares the configured USDC decimals with the token's
+// decimals() and logs a warning on mismatch. It is a no-op without RPC.
+func (s *Server) CheckUSDCDecimals(ctx context.Context) {
+	if s.RPC == nil || s.Config == nil || !common.IsHexAddress(s.Config.Contracts.USDC) {
+		return
+	}
+	usdc := common.HexToAddress(s.Config.Contracts.USDC)
+	data, err := s.ABIs.ERC20.Pack("decimals")
+	if err != nil {
+		return
+	}
+	out, err := s.RPC.CallContract(ctx, ethereum.CallMsg{To: &usdc, Data: data}, nil)
+	if err == nil {
+		var vals []any
+		if vals, err = s.ABIs.ERC20.Unpack("decimals", out); err == nil {
+			if d, ok := vals[0].(uint8); ok && int(d) != s.usdcDecimals() {
+				err = fmt.Errorf("configured usdcDecimals=%d but token reports %d", s.usdcDecimals(), d)
+			}
+		}
+	}
+	if err != nil {
+		log.Printf("[warn] usdc decimals check: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import "testing"
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		raw      string
+		decimals int
+		want     string
+	}{
+		{"0", 18, "0"},
+		{"0", 6, "0"},
+		{"1", 18, "0.000000000000000001"},
+		{"1", 6, "0.000001"},
+		{"999999", 6, "0.999999"},
+		{"1000000", 6, "1"},
+		{"1500000", 6, "1.5"},
+		{"1500000000000000000", 18, "1.5"},
+		{"1500000", 18, "0.0000000000015"},
+		{"123456789000000000000", 18, "123.456789"},
+		{"100000000000000000000000", 18, "100000"},
+		{"-2500000", 6, "-2.5"},
+		{"-1", 18, "-0.000000000000000001"},
+		{"42", 0, "42"},
+		{"42", -2, "4200"},
+		{"", 18, ""},
+		{"1.5", 18, ""},
+		{"0x10", 18, ""},
+		{"abc", 6, ""},
+	}
+	for _, tt := range tests {
+		if got := formatUnits(tt.raw, tt.decimals); got != tt.want {
+			t.Errorf("formatUnits(%q, %d) = %q, want %q", tt.raw, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestPoolDecimalsPrice(t *testing.T) {
+	// 1 USDC (6 decimals) per 1 token (18 decimals) is a raw ratio of 1e-12,
+	// so priceX18 is 1e6.
+	d := poolDecimals{TokenDecimals: 18, USDCDecimals: 6}
+	if got := d.price("1000000"); got != "1" {
+		t.Errorf("price = %q, want 1", got)
+	}
+	if got := d.pricePtr(nil); got != nil {
+		t.Errorf("pricePtr(nil) = %v, want nil", *got)
+	}
+}
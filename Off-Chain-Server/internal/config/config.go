@@ -2,7 +2,10 @@ package config
 
 import (
     "fmt"
+    "math/big"
     "os"
+    "strconv"
+    "strings"
 
     "gopkg.in/yaml.v3"
 )
@@ -15,16 +18,206 @@ type Config struct {
 	} `yaml:"rpc"`
 	Contracts struct {
 		Factory string `yaml:"factory"`
+		Router  string `yaml:"router"`
 		USDC    string `yaml:"usdc"`
+		// USDCDecimals is the USDC token's decimals (18 on Paxeer).
+		USDCDecimals int `yaml:"usdcDecimals"`
 	} `yaml:"contracts"`
 	Indexer struct {
 		StartBlock    uint64 `yaml:"startBlock"`
 		Confirmations uint64 `yaml:"confirmations"`
 		BatchSize     uint64 `yaml:"batchSize"`
+		// LeaderboardRefreshSecs is how often the leaderboard rollups are rebuilt.
+		LeaderboardRefreshSecs uint64 `yaml:"leaderboardRefreshSecs"`
 	} `yaml:"indexer"`
 	Postgres struct {
 		DSN string `yaml:"dsn"`
 	} `yaml:"postgres"`
+	API struct {
+		// AllowedOrigins may make credentialed (cookie) cross-origin requests:
+		// exact origins like "https://app.paxeer.app", or "https://*.paxeer.app"
+		// for any subdomain. Other origins get non-credentialed CORS only.
+		AllowedOrigins []string `yaml:"allowedOrigins"`
+		// TrustedProxies are IPs or CIDRs whose X-Forwarded-For is believed
+		// when determining the client IP.
+		TrustedProxies []string `yaml:"trustedProxies"`
+		RateLimit      struct {
+			// Backend is "memory" (default, per instance), "postgres"
+			// (shared by all instances) or "off".
+			Backend string `yaml:"backend"`
+			// Policies are matched in order; the first match applies.
+			Policies []RateLimitPolicy `yaml:"policies"`
+		} `yaml:"rateLimit"`
+	} `yaml:"api"`
+	Auth struct {
+		// Domains are the hosts (host[:port]) SIWE messages may be issued
+		// for. SIWE login is disabled while it is empty.
+		Domains []string `yaml:"domains"`
+		// ChainID SIWE messages must name (Paxeer: 80000).
+		ChainID int64 `yaml:"chainId"`
+		// MaxMessageAgeSecs bounds how old a SIWE message's Issued At may be.
+		MaxMessageAgeSecs uint64 `yaml:"maxMessageAgeSecs"`
+		// AllowLegacyLogin keeps accepting the old "paxeer-login:<nonce>"
+		// signature while clients migrate to SIWE.
+		AllowLegacyLogin bool `yaml:"allowLegacyLogin"`
+		// NonceTTLSecs is how long a nonce from /auth/nonce can be used.
+		NonceTTLSecs uint64 `yaml:"nonceTtlSecs"`
+		// JanitorIntervalSecs is how often expired sessions and nonces are deleted.
+		JanitorIntervalSecs uint64 `yaml:"janitorIntervalSecs"`
+	} `yaml:"auth"`
+	// Admins may revert any pool's metadata or any profile.
+	Admins []string `yaml:"admins"`
+	// Milestones lists bonding-curve milestones per factory. An entry without
+	// a factory applies to factories that have no entry of their own.
+	Milestones []FactoryMilestones `yaml:"milestones"`
+}
+
+// Milestone kinds.
+const (
+	MilestoneRealUSDC  = "realUSDC"
+	MilestoneMarketCap = "marketCap"
+)
+
+// Milestone is a threshold on a pool's real USDC reserve or market cap.
+type Milestone struct {
+	Name string `yaml:"name"`
+	Kind string `yaml:"kind"`
+	// Threshold is in whole USDC, e.g. "69000".
+	Threshold string `yaml:"threshold"`
+	// Graduation marks the milestone that completes the bonding curve.
+	Graduation bool `yaml:"graduation"`
+}
+
+type FactoryMilestones struct {
+	Factory    string      `yaml:"factory"`
+	Milestones []Milestone `yaml:"milestones"`
+}
+
+// ThresholdUnits returns the threshold in USDC base units.
+func (m Milestone) ThresholdUnits(usdcDecimals int) (*big.Int, error) {
+	r, ok := new(big.Rat).SetString(m.Threshold)
+	if !ok || r.Sign() <= 0 {
+		return nil, fmt.Errorf("milestone %q: invalid threshold %q", m.Name, m.Threshold)
+	}
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(usdcDecimals)), nil)))
+	return new(big.Int).Quo(r.Num(), r.Denom()), nil
+}
+
+// MilestonesFor returns the milestones configured for a factory, falling back
+// to the entry without a factory.
+func (c *Config) MilestonesFor(factory string) []Milestone {
+	var def []Milestone
+	for _, fm := range c.Milestones {
+		if fm.Factory == "" {
+			def = fm.Milestones
+		} else if strings.EqualFold(fm.Factory, factory) {
+			return fm.Milestones
+		}
+	}
+	return def
+}
+
+// Rate limit backends.
+const (
+	RateLimitMemory   = "memory"
+	RateLimitPostgres = "postgres"
+	RateLimitOff      = "off"
+)
+
+// RateLimitPolicy is a token bucket applied to matching requests.
+type RateLimitPolicy struct {
+	Name string `yaml:"name"`
+	// Method matches any method when empty.
+	Method string `yaml:"method"`
+	// Path is an exact path, a prefix ending in "*", or empty for any path.
+	Path string `yaml:"path"`
+	// Rate is the refill rate as "<count>/<s|m|h>", e.g. "30/m".
+	Rate string `yaml:"rate"`
+	// Burst is the bucket size (defaults to the count in Rate).
+	Burst int `yaml:"burst"`
+	// By lists the bucket keys: "ip" and/or "account" (signed-in address).
+	By []string `yaml:"by"`
+}
+
+// DefaultRateLimitPolicies apply when none are configured.
+var DefaultRateLimitPolicies = []RateLimitPolicy{
+	{Name: "auth", Path: "/auth/*", Rate: "20/m", Burst: 10, By: []string{"ip"}},
+	{Name: "upload", Method: "POST", Path: "/upload", Rate: "20/h", Burst: 5, By: []string{"ip", "account"}},
+	{Name: "comments", Method: "POST", Path: "/comments/*", Rate: "6/m", Burst: 3, By: []string{"ip", "account"}},
+	{Name: "default", Rate: "300/m", Burst: 60, By: []string{"ip"}},
+}
+
+// PerSecond returns the policy's refill rate in tokens per second.
+func (p RateLimitPolicy) PerSecond() (float64, error) {
+	count, unit, ok := strings.Cut(p.Rate, "/")
+	n, err := strconv.ParseFloat(count, 64)
+	if !ok || err != nil || n <= 0 {
+		return 0, fmt.Errorf("rate limit %q: invalid rate %q", p.Name, p.Rate)
+	}
+	switch unit {
+	case "s":
+		return n, nil
+	case "m":
+		return n / 60, nil
+	case "h":
+		return n / 3600, nil
+	}
+	return 0, fmt.Errorf("rate limit %q: invalid rate unit in %q", p.Name, p.Rate)
+}
+
+func (c *Config) validateRateLimits() error {
+	switch c.API.RateLimit.Backend {
+	case RateLimitMemory, RateLimitPostgres, RateLimitOff:
+	default:
+		return fmt.Errorf("rateLimit: unknown backend %q", c.API.RateLimit.Backend)
+	}
+	for i, p := range c.API.RateLimit.Policies {
+		if _, err := p.PerSecond(); err != nil {
+			return err
+		}
+		if p.Burst < 0 {
+			return fmt.Errorf("rate limit %q: invalid burst %d", p.Name, p.Burst)
+		}
+		if p.Burst == 0 {
+			n, _ := strconv.ParseFloat(strings.SplitN(p.Rate, "/", 2)[0], 64)
+			c.API.RateLimit.Policies[i].Burst = max(1, int(n))
+		}
+		for _, by := range p.By {
+			if by != "ip" && by != "account" {
+				return fmt.Errorf("rate limit %q: unknown key %q", p.Name, by)
+			}
+		}
+	}
+	return nil
+}
+
+// IsAdmin reports whether addr is one of the configured admins.
+func (c *Config) IsAdmin(addr string) bool {
+	for _, a := range c.Admins {
+		if strings.EqualFold(a, addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) validateMilestones() error {
+	for _, fm := range c.Milestones {
+		names := map[string]bool{}
+		for _, m := range fm.Milestones {
+			if m.Name == "" || names[m.Name] {
+				return fmt.Errorf("milestones: missing or duplicate name %q", m.Name)
+			}
+			names[m.Name] = true
+			if m.Kind != MilestoneRealUSDC && m.Kind != MilestoneMarketCap {
+				return fmt.Errorf("milestone %q: unknown kind %q", m.Name, m.Kind)
+			}
+			if _, err := m.ThresholdUnits(c.Contracts.USDCDecimals); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func Load(path string) (*Config, error) {
@@ -47,9 +240,45 @@ func Load(path string) (*Config, error) {
 	if v := os.Getenv("PAXEER_FACTORY"); v != "" {
 		c.Contracts.Factory = v
 	}
+	if v := os.Getenv("PAXEER_ROUTER"); v != "" {
+		c.Contracts.Router = v
+	}
 	if v := os.Getenv("PAXEER_USDC"); v != "" {
 		c.Contracts.USDC = v
 	}
+	if v := os.Getenv("PAXEER_USDC_DECIMALS"); v != "" {
+		if parsed, perr := parseUint(v); perr == nil {
+			c.Contracts.USDCDecimals = int(parsed)
+		}
+	}
+	if v := os.Getenv("PAXEER_API_ALLOWED_ORIGINS"); v != "" {
+		c.API.AllowedOrigins = strings.Split(v, ",")
+	}
+	if v := os.Getenv("PAXEER_API_TRUSTED_PROXIES"); v != "" {
+		c.API.TrustedProxies = strings.Split(v, ",")
+	}
+	if v := os.Getenv("PAXEER_API_RATE_LIMIT"); v != "" {
+		c.API.RateLimit.Backend = v
+	}
+	if v := os.Getenv("PAXEER_AUTH_DOMAINS"); v != "" {
+		c.Auth.Domains = strings.Split(v, ",")
+	}
+	if v := os.Getenv("PAXEER_CHAIN_ID"); v != "" {
+		if parsed, perr := parseUint(v); perr == nil {
+			c.Auth.ChainID = int64(parsed)
+		}
+	}
+	if v := os.Getenv("PAXEER_AUTH_ALLOW_LEGACY"); v != "" {
+		c.Auth.AllowLegacyLogin = v == "1" || strings.EqualFold(v, "true")
+	}
+	if v := os.Getenv("PAXEER_AUTH_NONCE_TTL_SECS"); v != "" {
+		if parsed, perr := parseUint(v); perr == nil {
+			c.Auth.NonceTTLSecs = parsed
+		}
+	}
+	if v := os.Getenv("PAXEER_ADMINS"); v != "" {
+		c.Admins = strings.Split(v, ",")
+	}
 	if v := os.Getenv("PAXEER_DB_DSN"); v != "" {
 		c.Postgres.DSN = v
 	}
@@ -68,6 +297,11 @@ func Load(path string) (*Config, error) {
 			c.Indexer.BatchSize = parsed
 		}
 	}
+	if v := os.Getenv("PAXEER_LEADERBOARD_REFRESH_SECS"); v != "" {
+		if parsed, perr := parseUint(v); perr == nil {
+			c.Indexer.LeaderboardRefreshSecs = parsed
+		}
+	}
 	// defaults
 	if c.Indexer.Confirmations == 0 {
 		c.Indexer.Confirmations = 2
@@ -75,6 +309,36 @@ func Load(path string) (*Config, error) {
 	if c.Indexer.BatchSize == 0 {
 		c.Indexer.BatchSize = 5000
 	}
+	if c.Auth.ChainID == 0 {
+		c.Auth.ChainID = 80000
+	}
+	if c.Auth.MaxMessageAgeSecs == 0 {
+		c.Auth.MaxMessageAgeSecs = 600
+	}
+	if c.Auth.NonceTTLSecs == 0 {
+		c.Auth.NonceTTLSecs = 600
+	}
+	if c.Auth.JanitorIntervalSecs == 0 {
+		c.Auth.JanitorIntervalSecs = 300
+	}
+	if c.Indexer.LeaderboardRefreshSecs == 0 {
+		c.Indexer.LeaderboardRefreshSecs = 60
+	}
+	if c.Contracts.USDCDecimals == 0 {
+		c.Contracts.USDCDecimals = 18
+	}
+	if c.API.RateLimit.Backend == "" {
+		c.API.RateLimit.Backend = RateLimitMemory
+	}
+	if len(c.API.RateLimit.Policies) == 0 {
+		c.API.RateLimit.Policies = append([]RateLimitPolicy(nil), DefaultRateLimitPolicies...)
+	}
+	if err := c.validateMilestones(); err != nil {
+		return nil, err
+	}
+	if err := c.validateRateLimits(); err != nil {
+		return nil, err
+	}
     return &c, nil
 }
 
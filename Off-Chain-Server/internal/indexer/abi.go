@@ -13,6 +13,8 @@ type ABIs struct {
 	Factory abi.ABI
 	Pool    abi.ABI
 	Oracle  abi.ABI
+	Router  abi.ABI
+	ERC20   abi.ABI
 
 	// Event IDs cache
 	SigPoolCreated    string
@@ -55,7 +57,15 @@ func LoadABIs() (*ABIs, error) {
 	if err != nil {
 		return nil, fmt.Errorf("load oracle abi: %w", err)
 	}
-	out := &ABIs{Factory: f, Pool: p, Oracle: o}
+	rt, err := loadABI("LaunchpadRouter")
+	if err != nil {
+		return nil, fmt.Errorf("load router abi: %w", err)
+	}
+	erc, err := loadABI("ERC20")
+	if err != nil {
+		return nil, fmt.Errorf("load erc20 abi: %w", err)
+	}
+	out := &ABIs{Factory: f, Pool: p, Oracle: o, Router: rt, ERC20: erc}
 	out.SigPoolCreated = f.Events["PoolCreated"].ID.String()
 	out.SigPriceUpdate = p.Events["PriceUpdate"].ID.String()
 	out.SigSync = p.Events["Sync"].ID.String()
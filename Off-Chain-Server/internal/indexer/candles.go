@@ -0,0 +1,150 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CandleResolution is one materialized candles_* rollup table.
+type CandleResolution struct {
+	Table   string
+	Seconds int64
+}
+
+// CandleResolutions lists the rollups finest first.
+var CandleResolutions = []CandleResolution{
+	{Table: "candles_1m", Seconds: 60},
+	{Table: "candles_5m", Seconds: 300},
+	{Table: "candles_1h", Seconds: 3600},
+	{Table: "candles_1d", Seconds: 86400},
+}
+
+// swapPriceX18 returns the execution price of a swap: the PriceUpdate emitted
+// earlier in the same transaction, or amountUSDC/amountToken when missing.
+func swapPriceX18(ctx context.Context, tx pgx.Tx, poolAddr, txHash string, logIndex int, usdc, tok *big.Int) (*big.Int, error) {
+	var price string
+	err := tx.QueryRow(ctx, `SELECT price_x18 FROM price_updates WHERE pool_address = $1 AND tx_hash = $2 AND log_index < $3 ORDER BY log_index DESC LIMIT 1`, poolAddr, txHash, logIndex).Scan(&price)
+	if err == nil {
+		if p, ok := new(big.Int).SetString(price, 10); ok {
+			return p, nil
+		}
+	} else if err != pgx.ErrNoRows {
+		return nil, err
+	}
+	if tok.Sign() == 0 {
+		return nil, nil
+	}
+	p := new(big.Int).Mul(usdc, big.NewInt(1e18))
+	return p.Quo(p, tok), nil
+}
+
+// applySwapToCandles folds a single swap into every candle rollup.
+func applySwapToCandles(ctx context.Context, tx pgx.Tx, poolAddr string, usdcToToken bool, amountIn, amountOut, txHash string, blockNumber int64, logIndex int, blockTime time.Time) error {
+	in, ok1 := new(big.Int).SetString(amountIn, 10)
+	out, ok2 := new(big.Int).SetString(amountOut, 10)
+	if !ok1 || !ok2 {
+		return fmt.Errorf("invalid swap amounts %q/%q", amountIn, amountOut)
+	}
+	usdc, tok := in, out
+	if !usdcToToken {
+		usdc, tok = out, in
+	}
+	price, err := swapPriceX18(ctx, tx, poolAddr, txHash, logIndex, usdc, tok)
+	if err != nil || price == nil {
+		return err
+	}
+	return upsertCandles(ctx, tx, poolAddr, price.String(), usdc.String(), tok.String(), 1, blockNumber, logIndex, blockTime)
+}
+
+// applyPriceToCandles folds a PriceUpdate into every candle rollup, moving
+// OHLC without adding volume or trades. Liquidity changes emit one without
+// a swap.
+func applyPriceToCandles(ctx context.Context, tx pgx.Tx, poolAddr, priceX18 string, blockNumber int64, logIndex int, blockTime time.Time) error {
+	return upsertCandles(ctx, tx, poolAddr, priceX18, "0", "0", 0, blockNumber, logIndex, blockTime)
+}
+
+func upsertCandles(ctx context.Context, tx pgx.Tx, poolAddr, price, volumeUSDC, volumeToken string, trades int, blockNumber int64, logIndex int, blockTime time.Time) error {
+	for _, res := range CandleResolutions {
+		bucket := time.Unix(blockTime.Unix()/res.Seconds*res.Seconds, 0).UTC()
+		_, err := tx.Exec(ctx, `
+			INSERT INTO `+res.Table+` AS c (pool_address, bucket_time, open, high, low, close, volume_usdc, volume_token, trades, open_block, open_log, close_block, close_log)
+			VALUES($1,$2,$3,$3,$3,$3,$4,$5,$6,$7,$8,$7,$8)
+			ON CONFLICT(pool_address, bucket_time) DO UPDATE SET
+				high = GREATEST(c.high, EXCLUDED.high),
+				low = LEAST(c.low, EXCLUDED.low),
+				open = CASE WHEN (EXCLUDED.open_block, EXCLUDED.open_log) < (c.open_block, c.open_log) THEN EXCLUDED.open ELSE c.open END,
+				open_block = CASE WHEN (EXCLUDED.open_block, EXCLUDED.open_log) < (c.open_block, c.open_log) THEN EXCLUDED.open_block ELSE c.open_block END,
+				open_log = CASE WHEN (EXCLUDED.open_block, EXCLUDED.open_log) < (c.open_block, c.open_log) THEN EXCLUDED.open_log ELSE c.open_log END,
+				close = CASE WHEN (EXCLUDED.close_block, EXCLUDED.close_log) > (c.close_block, c.close_log) THEN EXCLUDED.close ELSE c.close END,
+				close_block = CASE WHEN (EXCLUDED.close_block, EXCLUDED.close_log) > (c.close_block, c.close_log) THEN EXCLUDED.close_block ELSE c.close_block END,
+				close_log = CASE WHEN (EXCLUDED.close_block, EXCLUDED.close_log) > (c.close_block, c.close_log) THEN EXCLUDED.close_log ELSE c.close_log END,
+				volume_usdc = c.volume_usdc + EXCLUDED.volume_usdc,
+				volume_token = c.volume_token + EXCLUDED.volume_token,
+				trades = c.trades + EXCLUDED.trades
+		`, poolAddr, bucket, price, volumeUSDC, volumeToken, trades, blockNumber, logIndex)
+		if err != nil {
+			return fmt.Errorf("upsert %s: %w", res.Table, err)
+		}
+	}
+	return nil
+}
+
+// RebuildCandles recomputes every candle rollup from swaps and price updates.
+// An empty poolAddr rebuilds all pools.
+func (r *Repo) RebuildCandles(ctx context.Context, poolAddr string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	for _, res := range CandleResolutions {
+		if _, err := tx.Exec(ctx, `DELETE FROM `+res.Table+` WHERE $1 = '' OR pool_address = $1`, poolAddr); err != nil {
+			return fmt.Errorf("clear %s: %w", res.Table, err)
+		}
+		_, err := tx.Exec(ctx, `
+			WITH t AS (
+				SELECT s.pool_address, s.block_time, s.block_number, s.log_index,
+					CASE WHEN s.usdc_to_token THEN s.amount_in ELSE s.amount_out END AS usdc,
+					CASE WHEN s.usdc_to_token THEN s.amount_out ELSE s.amount_in END AS tok,
+					p.price_x18, 1 AS trades
+				FROM swaps s
+				LEFT JOIN LATERAL (
+					SELECT pu.price_x18 FROM price_updates pu
+					WHERE pu.pool_address = s.pool_address AND pu.tx_hash = s.tx_hash AND pu.log_index < s.log_index
+					ORDER BY pu.log_index DESC LIMIT 1
+				) p ON TRUE
+				WHERE s.block_time IS NOT NULL AND ($1 = '' OR s.pool_address = $1)
+				UNION ALL
+				-- Price updates move OHLC only.
+				SELECT pu.pool_address, pu.block_time, pu.block_number, pu.log_index, 0, 0, pu.price_x18, 0
+				FROM price_updates pu
+				WHERE pu.block_time IS NOT NULL AND ($1 = '' OR pu.pool_address = $1)
+			), priced AS (
+				SELECT pool_address, block_number, log_index, usdc, tok, trades,
+					to_timestamp(floor(extract(epoch from block_time) / $2) * $2) AS bucket_time,
+					COALESCE(price_x18, CASE WHEN tok > 0 THEN floor(usdc * 1e18 / tok) END) AS price
+				FROM t
+			)
+			INSERT INTO `+res.Table+`(pool_address, bucket_time, open, high, low, close, volume_usdc, volume_token, trades, open_block, open_log, close_block, close_log)
+			SELECT pool_address, bucket_time,
+				(ARRAY_AGG(price ORDER BY block_number ASC, log_index ASC))[1],
+				MAX(price), MIN(price),
+				(ARRAY_AGG(price ORDER BY block_number DESC, log_index DESC))[1],
+				SUM(usdc), SUM(tok), SUM(trades),
+				(ARRAY_AGG(block_number ORDER BY block_number ASC, log_index ASC))[1],
+				(ARRAY_AGG(log_index ORDER BY block_number ASC, log_index ASC))[1],
+				(ARRAY_AGG(block_number ORDER BY block_number DESC, log_index DESC))[1],
+				(ARRAY_AGG(log_index ORDER BY block_number DESC, log_index DESC))[1]
+			FROM priced WHERE price IS NOT NULL
+			GROUP BY pool_address, bucket_time
+		`, poolAddr, res.Seconds)
+		if err != nil {
+			return fmt.Errorf("rebuild %s: %w", res.Table, err)
+		}
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,97 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// draftFields are the metadata_drafts columns copied into pool_metadata; they
+// match the fields versioned in metadata_revisions.
+var draftFields = []string{"name", "symbol", "description", "website_url", "twitter_url", "telegram_url", "logo_url", "banner_url"}
+
+// PromoteDrafts copies pending drafts whose token and creator match an
+// indexed pool into pool_metadata, recording revision "draft". Pools that
+// already have metadata keep it and the draft is marked skipped. An empty
+// pool promotes every eligible draft. It returns the number promoted.
+func (r *Repo) PromoteDrafts(ctx context.Context, poolAddr string) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, `
+		SELECT p.pool_address, p.token_address, d.creator, d.name, d.symbol, d.description, d.website_url, d.twitter_url, d.telegram_url, d.logo_url, d.banner_url
+		FROM metadata_drafts d
+		JOIN pools p ON p.token_address = d.token_address AND p.creator_address = d.creator
+		WHERE d.status = 'pending' AND ($1 = '' OR p.pool_address = $1)
+		FOR UPDATE OF d`, poolAddr)
+	if err != nil {
+		return 0, err
+	}
+	type draft struct {
+		pool, token, creator string
+		values               [8]*string
+	}
+	var drafts []draft
+	for rows.Next() {
+		var d draft
+		dest := []any{&d.pool, &d.token, &d.creator}
+		for i := range d.values {
+			dest = append(dest, &d.values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		drafts = append(drafts, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	promoted := 0
+	for _, d := range drafts {
+		var inserted string
+		err := tx.QueryRow(ctx, `
+			INSERT INTO pool_metadata(pool_address, token_address, name, symbol, description, website_url, twitter_url, telegram_url, logo_url, banner_url, created_by, updated_by)
+			VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$11)
+			ON CONFLICT(pool_address) DO NOTHING
+			RETURNING pool_address`, d.pool, d.token, d.values[0], d.values[1], d.values[2], d.values[3], d.values[4], d.values[5], d.values[6], d.values[7], d.creator).Scan(&inserted)
+		status := "promoted"
+		if err == pgx.ErrNoRows {
+			status = "skipped"
+		} else if err != nil {
+			return 0, err
+		} else {
+			snapshot := make(map[string]*string, len(draftFields))
+			diff := make(map[string]map[string]*string)
+			for i, f := range draftFields {
+				snapshot[f] = d.values[i]
+				if d.values[i] != nil {
+					diff[f] = map[string]*string{"from": nil, "to": d.values[i]}
+				}
+			}
+			snapJSON, _ := json.Marshal(snapshot)
+			diffJSON, _ := json.Marshal(diff)
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO metadata_revisions(entity, entity_key, revision, editor, source, snapshot, diff)
+				SELECT 'pool', $1, COALESCE(MAX(revision), 0) + 1, $2, 'draft', $3, $4
+				FROM metadata_revisions WHERE entity = 'pool' AND entity_key = $1`, d.pool, d.creator, snapJSON, diffJSON); err != nil {
+				return 0, err
+			}
+			promoted++
+		}
+		if _, err := tx.Exec(ctx, `UPDATE metadata_drafts SET status = $3, pool_address = $4, promoted_at = NOW() WHERE token_address = $1 AND creator = $2`, d.token, d.creator, status, d.pool); err != nil {
+			return 0, err
+		}
+		if status == "skipped" {
+			log.Printf("[drafts] pool %s already has metadata; draft by %s skipped", d.pool, d.creator)
+		}
+	}
+	return promoted, tx.Commit(ctx)
+}
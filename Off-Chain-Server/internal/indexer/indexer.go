@@ -14,6 +14,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/paxeer/offchain-server/internal/config"
 )
 
 type Indexer struct {
@@ -29,9 +30,15 @@ type Indexer struct {
 	Confirmations uint64
 	BatchSize     uint64
 
+	// MilestonesFor returns the bonding-curve milestones tracked on Sync for
+	// pools of a factory, with thresholds scaled by USDCDecimals.
+	MilestonesFor func(factory string) []config.Milestone
+	USDCDecimals  int
+
 	mu       sync.RWMutex
 	pools    map[common.Address]struct{}
 	oracles  map[common.Address]common.Address // oracle -> pool
+	senders  map[common.Hash]common.Address    // tx -> sender
 	lastHead uint64
 }
 
@@ -60,6 +67,7 @@ func NewIndexer(httpURL, wsURL string, factory string, db *pgxpool.Pool, abis *A
 		BatchSize:     batch,
 		pools:        make(map[common.Address]struct{}),
 		oracles:      make(map[common.Address]common.Address),
+		senders:      make(map[common.Hash]common.Address),
 	}
 	return ix, nil
 }
@@ -88,14 +96,14 @@ func (ix *Indexer) safeHead(ctx context.Context) (uint64, error) {
 	return head - ix.Confirmations, nil
 }
 
-func (ix *Indexer) ensurePool(pool, token, oracle common.Address, createdBlock uint64, txHash common.Hash, blockTime *time.Time) {
+func (ix *Indexer) ensurePool(pool, token, oracle, factory common.Address, createdBlock uint64, txHash common.Hash, blockTime *time.Time) {
 	ix.mu.Lock()
 	defer ix.mu.Unlock()
 	if _, ok := ix.pools[pool]; !ok {
 		ix.pools[pool] = struct{}{}
 	}
 	ix.oracles[oracle] = pool
-	_ = ix.Repo.UpsertPool(context.Background(), pool.Hex(), token.Hex(), oracle.Hex(), int64(createdBlock), txHash.Hex(), blockTime)
+	_ = ix.Repo.UpsertPool(context.Background(), pool.Hex(), token.Hex(), oracle.Hex(), factory.Hex(), int64(createdBlock), txHash.Hex(), blockTime)
 }
 
 func (ix *Indexer) Backfill(ctx context.Context, start uint64) error {
@@ -251,7 +259,8 @@ func (ix *Indexer) handleFactoryLog(ctx context.Context, lg types.Log) error {
 		t := time.Unix(int64(h.Time), 0).UTC()
 		blkTime = &t
 	}
-	ix.ensurePool(pool, token, oracle, lg.BlockNumber, lg.TxHash, blkTime)
+	ix.ensurePool(pool, token, oracle, lg.Address, lg.BlockNumber, lg.TxHash, blkTime)
+	ix.loadPoolInfo(ctx, pool, token)
 	return nil
 }
 
@@ -282,6 +291,9 @@ func (ix *Indexer) handlePoolLog(ctx context.Context, lg types.Log) error {
 		rusdc := out.ReserveUSDC.String()
 		rtok := out.ReserveToken.String()
 		_ = ix.Repo.UpdatePoolSnapshot(ctx, poolAddr, &rusdc, &rtok, nil, nil)
+		if err := ix.trackMilestones(ctx, poolAddr, lg.TxHash.Hex(), int64(lg.BlockNumber), blkTime); err != nil {
+			log.Printf("[warn] pool %s milestones: %v", poolAddr, err)
+		}
 	case ix.ABIs.SigSwap:
 		out := struct{ AmountIn, AmountOut *big.Int; UsdcToToken bool }{}
 		if err := unpack(ix.ABIs.Pool, "Swap", lg, &out); err != nil { return err }
@@ -292,7 +304,7 @@ func (ix *Indexer) handlePoolLog(ctx context.Context, lg types.Log) error {
 		var blkTime *time.Time
 		h, err := ix.HTTP.HeaderByHash(ctx, lg.BlockHash)
 		if err == nil && h != nil { t := time.Unix(int64(h.Time), 0).UTC(); blkTime = &t }
-		return ix.Repo.InsertSwap(ctx, poolAddr, sender, out.UsdcToToken, out.AmountIn.String(), out.AmountOut.String(), to, lg.TxHash.Hex(), int64(lg.BlockNumber), int(lg.Index), blkTime, true)
+		return ix.Repo.InsertSwap(ctx, poolAddr, sender, ix.traderOf(ctx, lg.TxHash), out.UsdcToToken, out.AmountIn.String(), out.AmountOut.String(), to, lg.TxHash.Hex(), int64(lg.BlockNumber), int(lg.Index), blkTime, true)
 	case ix.ABIs.SigAddLiquidity:
 		out := struct{ AmountUSDC, AmountToken, LpMinted *big.Int }{}
 		if err := unpack(ix.ABIs.Pool, "AddLiquidity", lg, &out); err != nil { return err }
@@ -300,7 +312,7 @@ func (ix *Indexer) handlePoolLog(ctx context.Context, lg types.Log) error {
 		var blkTime *time.Time
 		h, err := ix.HTTP.HeaderByHash(ctx, lg.BlockHash)
 		if err == nil && h != nil { t := time.Unix(int64(h.Time), 0).UTC(); blkTime = &t }
-		return ix.Repo.InsertLiquidity(ctx, lg.Address.Hex(), "add", provider, out.AmountUSDC.String(), out.AmountToken.String(), out.LpMinted.String(), lg.TxHash.Hex(), int64(lg.BlockNumber), int(lg.Index), blkTime, true)
+		return ix.Repo.InsertLiquidity(ctx, lg.Address.Hex(), "add", provider, ix.traderOf(ctx, lg.TxHash), out.AmountUSDC.String(), out.AmountToken.String(), out.LpMinted.String(), lg.TxHash.Hex(), int64(lg.BlockNumber), int(lg.Index), blkTime, true)
 	case ix.ABIs.SigRemoveLiquidity:
 		out := struct{ LpBurned, AmountUSDC, AmountToken *big.Int }{}
 		if err := unpack(ix.ABIs.Pool, "RemoveLiquidity", lg, &out); err != nil { return err }
@@ -308,7 +320,7 @@ func (ix *Indexer) handlePoolLog(ctx context.Context, lg types.Log) error {
 		var blkTime *time.Time
 		h, err := ix.HTTP.HeaderByHash(ctx, lg.BlockHash)
 		if err == nil && h != nil { t := time.Unix(int64(h.Time), 0).UTC(); blkTime = &t }
-		return ix.Repo.InsertLiquidity(ctx, lg.Address.Hex(), "remove", provider, out.AmountUSDC.String(), out.AmountToken.String(), out.LpBurned.String(), lg.TxHash.Hex(), int64(lg.BlockNumber), int(lg.Index), blkTime, true)
+		return ix.Repo.InsertLiquidity(ctx, lg.Address.Hex(), "remove", provider, ix.traderOf(ctx, lg.TxHash), out.AmountUSDC.String(), out.AmountToken.String(), out.LpBurned.String(), lg.TxHash.Hex(), int64(lg.BlockNumber), int(lg.Index), blkTime, true)
 	case ix.ABIs.SigCollectCreatorFees:
 		out := struct{ AmountUSDC *big.Int }{}
 		if err := unpack(ix.ABIs.Pool, "CollectCreatorFees", lg, &out); err != nil { return err }
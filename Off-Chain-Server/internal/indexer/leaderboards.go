@@ -0,0 +1,128 @@
+package indexer
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// LeaderboardPeriod is a trailing window the leaderboards are rolled up for;
+// a zero Duration means all time.
+type LeaderboardPeriod struct {
+	Name     string
+	Duration time.Duration
+}
+
+var LeaderboardPeriods = []LeaderboardPeriod{
+	{Name: "24h", Duration: 24 * time.Hour},
+	{Name: "7d", Duration: 7 * 24 * time.Hour},
+	{Name: "all"},
+}
+
+const (
+	refreshLeaderboardTraders = `
+		INSERT INTO leaderboard_traders(period, trader, volume_usdc, realized_pnl_usdc, trades, pools, refreshed_at)
+		SELECT $1, trader, SUM(CASE WHEN usdc_to_token THEN amount_in ELSE amount_out END),
+			COALESCE(SUM(realized_pnl), 0), COUNT(*), COUNT(DISTINCT pool_address), $3
+		FROM swaps
+		WHERE trader IS NOT NULL AND ($2::timestamptz IS NULL OR block_time >= $2)
+		GROUP BY trader`
+
+	refreshLeaderboardCreators = `
+		WITH owned AS (
+			SELECT creator_address AS creator, COUNT(*) AS pools FROM pools WHERE creator_address IS NOT NULL GROUP BY creator_address
+		), collected AS (
+			SELECT p.creator_address AS creator, SUM(f.amount_usdc) AS fees
+			FROM creator_fees f JOIN pools p ON p.pool_address = f.pool_address
+			WHERE p.creator_address IS NOT NULL AND ($2::timestamptz IS NULL OR f.block_time >= $2)
+			GROUP BY p.creator_address
+		), vol AS (
+			SELECT p.creator_address AS creator, SUM(CASE WHEN s.usdc_to_token THEN s.amount_in ELSE s.amount_out END) AS volume,
+				SUM(creator_fee_usdc(s.usdc_to_token, s.amount_in, s.amount_out)) AS fees, COUNT(*) AS trades
+			FROM swaps s JOIN pools p ON p.pool_address = s.pool_address
+			WHERE p.creator_address IS NOT NULL AND ($2::timestamptz IS NULL OR s.block_time >= $2)
+			GROUP BY p.creator_address
+		)
+		INSERT INTO leaderboard_creators(period, creator, fees_usdc, fees_collected_usdc, volume_usdc, trades, pools, refreshed_at)
+		SELECT $1, o.creator, COALESCE(v.fees, 0), COALESCE(f.fees, 0), COALESCE(v.volume, 0), COALESCE(v.trades, 0), o.pools, $3
+		FROM owned o LEFT JOIN collected f ON f.creator = o.creator LEFT JOIN vol v ON v.creator = o.creator`
+
+	refreshLeaderboardPools = `
+		WITH vol AS (
+			SELECT pool_address, SUM(CASE WHEN usdc_to_token THEN amount_in ELSE amount_out END) AS volume,
+				COUNT(*) AS trades, COUNT(DISTINCT trader) AS traders
+			FROM swaps WHERE $2::timestamptz IS NULL OR block_time >= $2
+			GROUP BY pool_address
+		), first_buys AS (
+			SELECT pool_address, trader, MIN(block_time) AS first_at
+			FROM swaps WHERE usdc_to_token AND trader IS NOT NULL
+			GROUP BY pool_address, trader
+		), grown AS (
+			SELECT pool_address, COUNT(*) AS new_holders FROM first_buys
+			WHERE $2::timestamptz IS NULL OR first_at >= $2
+			GROUP BY pool_address
+		), held AS (
+			SELECT pool_address, COUNT(*) AS holders FROM account_positions WHERE token_balance > 0 GROUP BY pool_address
+		)
+		INSERT INTO leaderboard_pools(period, pool_address, volume_usdc, trades, traders, new_holders, holders, open_x18, refreshed_at)
+		SELECT $1, p.pool_address, COALESCE(v.volume, 0), COALESCE(v.trades, 0), COALESCE(v.traders, 0),
+			COALESCE(g.new_holders, 0), COALESCE(h.holders, 0), o.price_x18, $3
+		FROM pools p
+		LEFT JOIN vol v ON v.pool_address = p.pool_address
+		LEFT JOIN grown g ON g.pool_address = p.pool_address
+		LEFT JOIN held h ON h.pool_address = p.pool_address
+		LEFT JOIN LATERAL (
+			-- The last price at or before the period start, else the first price.
+			SELECT COALESCE(
+				(SELECT u.price_x18 FROM price_updates u WHERE u.pool_address = p.pool_address AND u.block_time <= $2 ORDER BY u.block_number DESC, u.log_index DESC LIMIT 1),
+				(SELECT u.price_x18 FROM price_updates u WHERE u.pool_address = p.pool_address ORDER BY u.block_number ASC, u.log_index ASC LIMIT 1)
+			) AS price_x18
+		) o ON TRUE`
+)
+
+// RefreshLeaderboards recomputes every leaderboard period from swaps,
+// creator_fees and account_positions in one transaction, so readers never
+// see a partially rebuilt board.
+func (r *Repo) RefreshLeaderboards(ctx context.Context) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	for _, table := range []string{"leaderboard_traders", "leaderboard_creators", "leaderboard_pools"} {
+		if _, err := tx.Exec(ctx, `DELETE FROM `+table); err != nil {
+			return err
+		}
+	}
+	now := time.Now().UTC()
+	for _, p := range LeaderboardPeriods {
+		var since *time.Time
+		if p.Duration > 0 {
+			t := now.Add(-p.Duration)
+			since = &t
+		}
+		for _, q := range []string{refreshLeaderboardTraders, refreshLeaderboardCreators, refreshLeaderboardPools} {
+			if _, err := tx.Exec(ctx, q, p.Name, since, now); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// RefreshLeaderboardsEvery refreshes the leaderboards now and then on every
+// interval until ctx is done. Failures are logged and retried on the next tick.
+func (ix *Indexer) RefreshLeaderboardsEvery(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if err := ix.Repo.RefreshLeaderboards(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("[warn] refresh leaderboards: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
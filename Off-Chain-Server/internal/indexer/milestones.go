@@ -0,0 +1,84 @@
+package indexer
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/paxeer/offchain-server/internal/config"
+)
+
+// GraduationMilestone returns the milestone progress is measured against:
+// the one flagged as graduation, else the last one configured.
+func GraduationMilestone(ms []config.Milestone) (config.Milestone, bool) {
+	for _, m := range ms {
+		if m.Graduation {
+			return m, true
+		}
+	}
+	if len(ms) == 0 {
+		return config.Milestone{}, false
+	}
+	return ms[len(ms)-1], true
+}
+
+// MilestoneValue picks the pool metric a milestone kind tracks.
+func MilestoneValue(kind string, realUSDC, marketCap *big.Int) *big.Int {
+	if kind == config.MilestoneMarketCap {
+		return marketCap
+	}
+	return realUSDC
+}
+
+// ProgressPct returns value/threshold as a percentage with two decimals, clamped to [0, 100].
+func ProgressPct(value, threshold *big.Int) string {
+	if threshold.Sign() <= 0 {
+		return "0.00"
+	}
+	r := new(big.Rat).SetFrac(new(big.Int).Mul(value, big.NewInt(100)), threshold)
+	if r.Cmp(big.NewRat(100, 1)) > 0 {
+		r.SetInt64(100)
+	} else if r.Sign() < 0 {
+		r.SetInt64(0)
+	}
+	return r.FloatString(2)
+}
+
+// trackMilestones recomputes a pool's progress after a Sync against the
+// milestones of the pool's factory and records the first crossing of each.
+// Crossings are never un-recorded when the pool later falls back below a
+// threshold.
+func (ix *Indexer) trackMilestones(ctx context.Context, poolAddr, txHash string, block int64, blockTime *time.Time) error {
+	if ix.MilestonesFor == nil {
+		return nil
+	}
+	factory, realUSDC, marketCap, err := ix.Repo.PoolProgressValues(ctx, poolAddr)
+	if err != nil {
+		return err
+	}
+	// Pools indexed before factories were recorded use the configured factory.
+	if factory == "" {
+		factory = ix.Factory.Hex()
+	}
+	milestones := ix.MilestonesFor(factory)
+	grad, ok := GraduationMilestone(milestones)
+	if !ok {
+		return nil
+	}
+	var crossed []CrossedMilestone
+	for _, m := range milestones {
+		th, err := m.ThresholdUnits(ix.USDCDecimals)
+		if err != nil {
+			return err
+		}
+		if MilestoneValue(m.Kind, realUSDC, marketCap).Cmp(th) >= 0 {
+			crossed = append(crossed, CrossedMilestone{Milestone: m, Threshold: th.String()})
+		}
+	}
+	th, err := grad.ThresholdUnits(ix.USDCDecimals)
+	if err != nil {
+		return err
+	}
+	progress := ProgressPct(MilestoneValue(grad.Kind, realUSDC, marketCap), th)
+	return ix.Repo.RecordProgress(ctx, poolAddr, progress, crossed, txHash, block, blockTime)
+}
@@ -0,0 +1,112 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// call performs a read-only contract call at the latest block and unpacks its outputs.
+func (ix *Indexer) call(ctx context.Context, contractABI abi.ABI, addr common.Address, method string, args ...any) ([]any, error) {
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := ix.HTTP.CallContract(ctx, ethereum.CallMsg{To: &addr, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("call %s: %w", method, err)
+	}
+	return contractABI.Unpack(method, out)
+}
+
+func (ix *Indexer) callBig(ctx context.Context, contractABI abi.ABI, addr common.Address, method string) (*big.Int, error) {
+	vals, err := ix.call(ctx, contractABI, addr, method)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := vals[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("call %s: unexpected output %T", method, vals[0])
+	}
+	return v, nil
+}
+
+// PoolInfo holds the pool parameters read from chain when a pool is discovered.
+type PoolInfo struct {
+	Creator       string
+	VirtualUSDC   string
+	VirtualToken  string
+	TokenDecimals int
+	TotalSupply   string
+}
+
+// loadPoolInfo reads a pool's immutable parameters once it is discovered.
+// With the creator known, the creator's metadata draft for the token (if
+// any) is promoted to the pool.
+func (ix *Indexer) loadPoolInfo(ctx context.Context, pool, token common.Address) {
+	info, err := ix.readPoolInfo(ctx, pool, token)
+	if err != nil {
+		log.Printf("[warn] pool %s info: %v", pool.Hex(), err)
+		return
+	}
+	if err := ix.Repo.UpdatePoolInfo(ctx, pool.Hex(), info); err != nil {
+		log.Printf("[warn] pool %s info: %v", pool.Hex(), err)
+		return
+	}
+	if n, err := ix.Repo.PromoteDrafts(ctx, pool.Hex()); err != nil {
+		log.Printf("[warn] pool %s draft: %v", pool.Hex(), err)
+	} else if n > 0 {
+		log.Printf("[drafts] promoted metadata draft for pool %s", pool.Hex())
+	}
+}
+
+func (ix *Indexer) readPoolInfo(ctx context.Context, pool, token common.Address) (PoolInfo, error) {
+	var info PoolInfo
+	vUSDC, err := ix.callBig(ctx, ix.ABIs.Pool, pool, "virtualReserveUSDC")
+	if err != nil {
+		return info, err
+	}
+	vToken, err := ix.callBig(ctx, ix.ABIs.Pool, pool, "virtualReserveToken")
+	if err != nil {
+		return info, err
+	}
+	vals, err := ix.call(ctx, ix.ABIs.Pool, pool, "creator")
+	if err != nil {
+		return info, err
+	}
+	creator, ok := vals[0].(common.Address)
+	if !ok {
+		return info, fmt.Errorf("call creator: unexpected output %T", vals[0])
+	}
+	vals, err = ix.call(ctx, ix.ABIs.ERC20, token, "decimals")
+	if err != nil {
+		return info, err
+	}
+	decimals, ok := vals[0].(uint8)
+	if !ok {
+		return info, fmt.Errorf("call decimals: unexpected output %T", vals[0])
+	}
+	supply, err := ix.callBig(ctx, ix.ABIs.ERC20, token, "totalSupply")
+	if err != nil {
+		return info, err
+	}
+	return PoolInfo{Creator: creator.Hex(), VirtualUSDC: vUSDC.String(), VirtualToken: vToken.String(), TokenDecimals: int(decimals), TotalSupply: supply.String()}, nil
+}
+
+// LoadMissingPoolInfo reads pool parameters for pools indexed before they
+// were tracked, so older pools get them too.
+func (ix *Indexer) LoadMissingPoolInfo(ctx context.Context) error {
+	pools, err := ix.Repo.PoolsMissingInfo(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range pools {
+		ix.loadPoolInfo(ctx, common.HexToAddress(p[0]), common.HexToAddress(p[1]))
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// position is a trader's average-cost position in one pool's token.
+type position struct {
+	Balance, Cost, Realized       *big.Int
+	Bought, Sold, Spent, Received *big.Int
+	Trades                        int
+	LastBlock                     int64
+	LastLog                       int
+}
+
+func newPosition() *position {
+	return &position{Balance: new(big.Int), Cost: new(big.Int), Realized: new(big.Int),
+		Bought: new(big.Int), Sold: new(big.Int), Spent: new(big.Int), Received: new(big.Int)}
+}
+
+// apply folds a swap into the position and returns the realized PnL for
+// sells (nil for buys). Tokens sold beyond the tracked balance came from
+// outside the pool and carry no cost basis.
+func (p *position) apply(usdcToToken bool, amountIn, amountOut *big.Int, block int64, logIndex int) *big.Int {
+	p.Trades++
+	p.LastBlock, p.LastLog = block, logIndex
+	if usdcToToken {
+		p.Spent.Add(p.Spent, amountIn)
+		p.Bought.Add(p.Bought, amountOut)
+		p.Cost.Add(p.Cost, amountIn)
+		p.Balance.Add(p.Balance, amountOut)
+		return nil
+	}
+	p.Sold.Add(p.Sold, amountIn)
+	p.Received.Add(p.Received, amountOut)
+	costOut := new(big.Int)
+	if p.Balance.Sign() > 0 {
+		if amountIn.Cmp(p.Balance) >= 0 {
+			costOut.Set(p.Cost)
+		} else {
+			costOut.Mul(p.Cost, amountIn).Quo(costOut, p.Balance)
+		}
+	}
+	p.Cost.Sub(p.Cost, costOut)
+	p.Balance.Sub(p.Balance, amountIn)
+	if p.Balance.Sign() < 0 {
+		p.Balance.SetInt64(0)
+	}
+	realized := new(big.Int).Sub(amountOut, costOut)
+	p.Realized.Add(p.Realized, realized)
+	return realized
+}
+
+func parseAmounts(vals ...string) ([]*big.Int, error) {
+	out := make([]*big.Int, len(vals))
+	for i, v := range vals {
+		n, ok := new(big.Int).SetString(v, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %q", v)
+		}
+		out[i] = n
+	}
+	return out, nil
+}
+
+// loadPosition reads and locks a position; it returns nil when none exists.
+func loadPosition(ctx context.Context, tx pgx.Tx, trader, poolAddr string) (*position, error) {
+	var raw [7]string
+	p := newPosition()
+	err := tx.QueryRow(ctx, `
+		SELECT token_balance::text, cost_basis_usdc::text, realized_pnl_usdc::text, bought_token::text, sold_token::text, spent_usdc::text, received_usdc::text, trades, last_block, last_log
+		FROM account_positions WHERE trader = $1 AND pool_address = $2 FOR UPDATE`, trader, poolAddr).
+		Scan(&raw[0], &raw[1], &raw[2], &raw[3], &raw[4], &raw[5], &raw[6], &p.Trades, &p.LastBlock, &p.LastLog)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	n, err := parseAmounts(raw[:]...)
+	if err != nil {
+		return nil, err
+	}
+	p.Balance, p.Cost, p.Realized, p.Bought, p.Sold, p.Spent, p.Received = n[0], n[1], n[2], n[3], n[4], n[5], n[6]
+	return p, nil
+}
+
+func savePosition(ctx context.Context, tx pgx.Tx, trader, poolAddr string, p *position) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO account_positions(trader, pool_address, token_balance, cost_basis_usdc, realized_pnl_usdc, bought_token, sold_token, spent_usdc, received_usdc, trades, last_block, last_log, updated_at)
+		VALUES($1,$2,$3::numeric,$4::numeric,$5::numeric,$6::numeric,$7::numeric,$8::numeric,$9::numeric,$10,$11,$12,NOW())
+		ON CONFLICT(trader, pool_address) DO UPDATE SET
+			token_balance = EXCLUDED.token_balance, cost_basis_usdc = EXCLUDED.cost_basis_usdc, realized_pnl_usdc = EXCLUDED.realized_pnl_usdc,
+			bought_token = EXCLUDED.bought_token, sold_token = EXCLUDED.sold_token, spent_usdc = EXCLUDED.spent_usdc, received_usdc = EXCLUDED.received_usdc,
+			trades = EXCLUDED.trades, last_block = EXCLUDED.last_block, last_log = EXCLUDED.last_log, updated_at = NOW()
+	`, trader, poolAddr, p.Balance.String(), p.Cost.String(), p.Realized.String(), p.Bought.String(), p.Sold.String(), p.Spent.String(), p.Received.String(), p.Trades, p.LastBlock, p.LastLog)
+	return err
+}
+
+// applySwapToPosition updates the trader's position inside the swap's
+// transaction and returns the sell's realized PnL. Swaps at or before the
+// position's last applied log are skipped so re-scans do not double count.
+func applySwapToPosition(ctx context.Context, tx pgx.Tx, trader, poolAddr string, usdcToToken bool, amountIn, amountOut string, blockNumber int64, logIndex int) (*string, error) {
+	amounts, err := parseAmounts(amountIn, amountOut)
+	if err != nil {
+		return nil, err
+	}
+	p, err := loadPosition(ctx, tx, trader, poolAddr)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		p = newPosition()
+	} else if blockNumber < p.LastBlock || (blockNumber == p.LastBlock && logIndex <= p.LastLog) {
+		return nil, nil
+	}
+	realized := p.apply(usdcToToken, amounts[0], amounts[1], blockNumber, logIndex)
+	if err := savePosition(ctx, tx, trader, poolAddr, p); err != nil {
+		return nil, err
+	}
+	if realized == nil {
+		return nil, nil
+	}
+	s := realized.String()
+	return &s, nil
+}
+
+// RebuildPositions recomputes account_positions and swaps.realized_pnl from
+// swaps in chain order, for one trader or for everyone when trader is "".
+func (r *Repo) RebuildPositions(ctx context.Context, trader string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx, `DELETE FROM account_positions WHERE $1 = '' OR trader = $1`, trader); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE swaps SET realized_pnl = NULL WHERE realized_pnl IS NOT NULL AND ($1 = '' OR trader = $1)`, trader); err != nil {
+		return err
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT id, trader, pool_address, usdc_to_token, amount_in::text, amount_out::text, block_number, log_index
+		FROM swaps WHERE trader IS NOT NULL AND ($1 = '' OR trader = $1)
+		ORDER BY block_number ASC, log_index ASC`, trader)
+	if err != nil {
+		return err
+	}
+	type key struct{ trader, pool string }
+	positions := map[key]*position{}
+	batch := &pgx.Batch{}
+	for rows.Next() {
+		var (
+			id               int64
+			k                key
+			usdcToToken      bool
+			amountIn, amtOut string
+			block            int64
+			logIndex         int
+		)
+		if err := rows.Scan(&id, &k.trader, &k.pool, &usdcToToken, &amountIn, &amtOut, &block, &logIndex); err != nil {
+			rows.Close()
+			return err
+		}
+		amounts, err := parseAmounts(amountIn, amtOut)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		p := positions[k]
+		if p == nil {
+			p = newPosition()
+			positions[k] = p
+		}
+		if realized := p.apply(usdcToToken, amounts[0], amounts[1], block, logIndex); realized != nil {
+			batch.Queue(`UPDATE swaps SET realized_pnl = $2::numeric WHERE id = $1`, id, realized.String())
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if batch.Len() > 0 {
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return err
+		}
+	}
+	for k, p := range positions {
+		if err := savePosition(ctx, tx, k.trader, k.pool, p); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
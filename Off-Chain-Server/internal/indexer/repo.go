@@ -2,27 +2,59 @@ package indexer
 
 import (
 	"context"
+	"fmt"
+	"math/big"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/paxeer/offchain-server/internal/config"
 )
 
+// PoolActivityChannel is the Postgres NOTIFY channel carrying the address of
+// a pool whose swaps or prices changed.
+const PoolActivityChannel = "pool_activity"
+
 type Repo struct{
 	pool *pgxpool.Pool
 }
 
 func NewRepo(pool *pgxpool.Pool) *Repo { return &Repo{pool: pool} }
 
-func (r *Repo) UpsertPool(ctx context.Context, poolAddr, tokenAddr, oracleAddr string, createdBlock int64, createdTx string, createdTime *time.Time) error {
+func (r *Repo) UpsertPool(ctx context.Context, poolAddr, tokenAddr, oracleAddr, factoryAddr string, createdBlock int64, createdTx string, createdTime *time.Time) error {
 	_, err := r.pool.Exec(ctx, `
-		INSERT INTO pools(pool_address, token_address, oracle_address, created_block, created_tx, created_time)
-		VALUES($1,$2,$3,$4,$5,$6)
-		ON CONFLICT(pool_address) DO UPDATE SET token_address = EXCLUDED.token_address, oracle_address = EXCLUDED.oracle_address
-	`, poolAddr, tokenAddr, oracleAddr, createdBlock, createdTx, createdTime)
+		INSERT INTO pools(pool_address, token_address, oracle_address, factory_address, created_block, created_tx, created_time)
+		VALUES($1,$2,$3,$4,$5,$6,$7)
+		ON CONFLICT(pool_address) DO UPDATE SET token_address = EXCLUDED.token_address, oracle_address = EXCLUDED.oracle_address, factory_address = EXCLUDED.factory_address
+	`, poolAddr, tokenAddr, oracleAddr, factoryAddr, createdBlock, createdTx, createdTime)
+	return err
+}
+
+// UpdatePoolInfo stores the immutable pool parameters read from chain.
+func (r *Repo) UpdatePoolInfo(ctx context.Context, poolAddr string, info PoolInfo) error {
+	_, err := r.pool.Exec(ctx, `UPDATE pools SET creator_address = $2, virtual_usdc = $3::numeric, virtual_token = $4::numeric, token_decimals = $5, total_supply = $6::numeric WHERE pool_address = $1`,
+		poolAddr, info.Creator, info.VirtualUSDC, info.VirtualToken, info.TokenDecimals, info.TotalSupply)
 	return err
 }
 
+// PoolsMissingInfo lists [pool, token] pairs whose immutable parameters have not been read yet.
+func (r *Repo) PoolsMissingInfo(ctx context.Context) ([][2]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT pool_address, token_address FROM pools WHERE creator_address IS NULL OR virtual_usdc IS NULL OR virtual_token IS NULL OR token_decimals IS NULL OR total_supply IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out [][2]string
+	for rows.Next() {
+		var p [2]string
+		if err := rows.Scan(&p[0], &p[1]); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
 func (r *Repo) UpdatePoolSnapshot(ctx context.Context, poolAddr string, reserveUSDC, reserveToken, spotX18, floorX18 *string) error {
     _, err := r.pool.Exec(ctx, `
         UPDATE pools SET
@@ -35,12 +67,34 @@ func (r *Repo) UpdatePoolSnapshot(ctx context.Context, poolAddr string, reserveU
     return err
 }
 
+// InsertPriceUpdate stores a PriceUpdate and folds it into the candle
+// rollups in one transaction. Replayed logs are ignored.
 func (r *Repo) InsertPriceUpdate(ctx context.Context, poolAddr, priceX18, floorX18, txHash string, blockNumber int64, logIndex int, blockTime *time.Time, confirmed bool) error {
-	_, err := r.pool.Exec(ctx, `
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	tag, err := tx.Exec(ctx, `
 		INSERT INTO price_updates(pool_address, price_x18, floor_x18, block_number, tx_hash, log_index, block_time, confirmed)
 		VALUES($1,$2,$3,$4,$5,$6,$7,$8)
+		ON CONFLICT(tx_hash, log_index) DO NOTHING
 	`, poolAddr, priceX18, floorX18, blockNumber, txHash, logIndex, blockTime, confirmed)
-	return err
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
+	if blockTime != nil {
+		if err := applyPriceToCandles(ctx, tx, poolAddr, priceX18, blockNumber, logIndex, *blockTime); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(ctx, `SELECT pg_notify($1, $2)`, PoolActivityChannel, poolAddr); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *Repo) InsertReserves(ctx context.Context, poolAddr, reserveUSDC, reserveToken, txHash string, blockNumber int64, logIndex int, blockTime *time.Time, confirmed bool) error {
@@ -51,19 +105,51 @@ func (r *Repo) InsertReserves(ctx context.Context, poolAddr, reserveUSDC, reserv
 	return err
 }
 
-func (r *Repo) InsertSwap(ctx context.Context, poolAddr, sender string, usdcToToken bool, amountIn, amountOut, recipient, txHash string, blockNumber int64, logIndex int, blockTime *time.Time, confirmed bool) error {
-	_, err := r.pool.Exec(ctx, `
-		INSERT INTO swaps(pool_address, sender, usdc_to_token, amount_in, amount_out, recipient, block_number, tx_hash, log_index, block_time, confirmed)
-		VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
-	`, poolAddr, sender, usdcToToken, amountIn, amountOut, recipient, blockNumber, txHash, logIndex, blockTime, confirmed)
-	return err
+// InsertSwap stores a swap and folds it into the candle rollups and, when the
+// trader is known, the trader's position, in one transaction. Replayed logs
+// (backfill restarts, overlapping polls) are ignored.
+// trader is the transaction sender, or "" when it could not be looked up.
+func (r *Repo) InsertSwap(ctx context.Context, poolAddr, sender, trader string, usdcToToken bool, amountIn, amountOut, recipient, txHash string, blockNumber int64, logIndex int, blockTime *time.Time, confirmed bool) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	var realized *string
+	if trader != "" {
+		if realized, err = applySwapToPosition(ctx, tx, trader, poolAddr, usdcToToken, amountIn, amountOut, blockNumber, logIndex); err != nil {
+			return err
+		}
+	}
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO swaps(pool_address, sender, usdc_to_token, amount_in, amount_out, recipient, block_number, tx_hash, log_index, block_time, confirmed, trader, realized_pnl)
+		VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,NULLIF($12,''),$13::numeric)
+		ON CONFLICT(tx_hash, log_index) DO NOTHING
+	`, poolAddr, sender, usdcToToken, amountIn, amountOut, recipient, blockNumber, txHash, logIndex, blockTime, confirmed, trader, realized)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		// Already stored; rolling back also drops the position update.
+		return nil
+	}
+	if blockTime != nil {
+		if err := applySwapToCandles(ctx, tx, poolAddr, usdcToToken, amountIn, amountOut, txHash, blockNumber, logIndex, *blockTime); err != nil {
+			return err
+		}
+	}
+	// Delivered to listeners on commit.
+	if _, err := tx.Exec(ctx, `SELECT pg_notify($1, $2)`, PoolActivityChannel, poolAddr); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func (r *Repo) InsertLiquidity(ctx context.Context, poolAddr, eventType, provider, amountUSDC, amountToken, lpAmount, txHash string, blockNumber int64, logIndex int, blockTime *time.Time, confirmed bool) error {
+func (r *Repo) InsertLiquidity(ctx context.Context, poolAddr, eventType, provider, trader, amountUSDC, amountToken, lpAmount, txHash string, blockNumber int64, logIndex int, blockTime *time.Time, confirmed bool) error {
 	_, err := r.pool.Exec(ctx, `
-		INSERT INTO liquidity_events(pool_address, event_type, provider, amount_usdc, amount_token, lp_amount, block_number, tx_hash, log_index, block_time, confirmed)
-		VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
-	`, poolAddr, eventType, provider, amountUSDC, amountToken, lpAmount, blockNumber, txHash, logIndex, blockTime, confirmed)
+		INSERT INTO liquidity_events(pool_address, event_type, provider, amount_usdc, amount_token, lp_amount, block_number, tx_hash, log_index, block_time, confirmed, trader)
+		VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,NULLIF($12,''))
+	`, poolAddr, eventType, provider, amountUSDC, amountToken, lpAmount, blockNumber, txHash, logIndex, blockTime, confirmed, trader)
 	return err
 }
 
@@ -91,3 +177,82 @@ func (r *Repo) LookupPoolByOracle(ctx context.Context, oracleAddr string) (strin
 	}
 	return poolAddr, err
 }
+
+// CrossedMilestone is a configured milestone with its threshold in USDC base units.
+type CrossedMilestone struct {
+	Milestone config.Milestone
+	Threshold string
+}
+
+// PoolProgressValues returns the pool's factory and the real USDC reserve and
+// market cap milestones are measured on. The factory is empty for pools
+// indexed before factories were recorded.
+func (r *Repo) PoolProgressValues(ctx context.Context, poolAddr string) (factory string, realUSDC, marketCap *big.Int, err error) {
+	var a, b string
+	err = r.pool.QueryRow(ctx, `
+		SELECT COALESCE(p.factory_address, ''), COALESCE(p.reserve_usdc, 0)::text, COALESCE(pv.market_cap, 0)::text
+		FROM pools p JOIN pool_valuations pv ON pv.pool_address = p.pool_address
+		WHERE p.pool_address = $1`, poolAddr).Scan(&factory, &a, &b)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	realUSDC, ok1 := new(big.Int).SetString(a, 10)
+	marketCap, ok2 := new(big.Int).SetString(b, 10)
+	if !ok1 || !ok2 {
+		return "", nil, nil, fmt.Errorf("pool %s: non-integer progress values %q, %q", poolAddr, a, b)
+	}
+	return factory, realUSDC, marketCap, nil
+}
+
+// RecordProgress stores the pool's progress and the first crossing of each milestone.
+func (r *Repo) RecordProgress(ctx context.Context, poolAddr, progressPct string, crossed []CrossedMilestone, txHash string, blockNumber int64, blockTime *time.Time) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx, `UPDATE pools SET progress_pct = $2::numeric WHERE pool_address = $1`, poolAddr, progressPct); err != nil {
+		return err
+	}
+	for _, c := range crossed {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO pool_milestones(pool_address, name, kind, threshold, graduation, block_number, tx_hash, block_time)
+			VALUES($1,$2,$3,$4::numeric,$5,$6,$7,$8)
+			ON CONFLICT(pool_address, name) DO NOTHING
+		`, poolAddr, c.Milestone.Name, c.Milestone.Kind, c.Threshold, c.Milestone.Graduation, blockNumber, txHash, blockTime)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// TxHashesMissingTrader lists transactions with swaps or liquidity events that have no trader.
+func (r *Repo) TxHashesMissingTrader(ctx context.Context) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT tx_hash FROM swaps WHERE trader IS NULL
+		UNION
+		SELECT tx_hash FROM liquidity_events WHERE trader IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var h string
+		if err := rows.Scan(&h); err != nil {
+			return nil, err
+		}
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
+
+// SetTrader fills in the trader of a transaction's swaps and liquidity events.
+func (r *Repo) SetTrader(ctx context.Context, txHash, trader string) error {
+	if _, err := r.pool.Exec(ctx, `UPDATE swaps SET trader = $2 WHERE tx_hash = $1 AND trader IS NULL`, txHash, trader); err != nil {
+		return err
+	}
+	_, err := r.pool.Exec(ctx, `UPDATE liquidity_events SET trader = $2 WHERE tx_hash = $1 AND trader IS NULL`, txHash, trader)
+	return err
+}
@@ -0,0 +1,86 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// maxSenderCache bounds the tx hash -> sender cache; a transaction's logs
+// arrive together, so a small cache avoids repeat lookups.
+const maxSenderCache = 4096
+
+// txSender returns the account that sent a transaction, i.e. the real trader
+// when a pool is called through the router.
+func (ix *Indexer) txSender(ctx context.Context, hash common.Hash) (common.Address, error) {
+	ix.mu.RLock()
+	from, ok := ix.senders[hash]
+	ix.mu.RUnlock()
+	if ok {
+		return from, nil
+	}
+	tx, _, err := ix.HTTP.TransactionByHash(ctx, hash)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("tx %s: %w", hash.Hex(), err)
+	}
+	from, err = types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("tx %s sender: %w", hash.Hex(), err)
+	}
+	ix.mu.Lock()
+	if len(ix.senders) >= maxSenderCache {
+		ix.senders = make(map[common.Hash]common.Address)
+	}
+	ix.senders[hash] = from
+	ix.mu.Unlock()
+	return from, nil
+}
+
+// traderOf is txSender for event handlers: lookup failures are logged and
+// yield "" so the event is still stored; BackfillTraders fills it in later.
+func (ix *Indexer) traderOf(ctx context.Context, hash common.Hash) string {
+	from, err := ix.txSender(ctx, hash)
+	if err != nil {
+		log.Printf("[warn] trader lookup: %v", err)
+		return ""
+	}
+	return from.Hex()
+}
+
+// BackfillTraders sets the trader of swaps and liquidity events stored
+// without one, e.g. before trader attribution existed, and rebuilds the
+// positions of the traders it attributed swaps to.
+func (ix *Indexer) BackfillTraders(ctx context.Context) error {
+	hashes, err := ix.Repo.TxHashesMissingTrader(ctx)
+	if err != nil {
+		return err
+	}
+	if len(hashes) > 0 {
+		log.Printf("[traders] backfilling %d transactions", len(hashes))
+	}
+	touched := map[string]bool{}
+	for _, h := range hashes {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		from, err := ix.txSender(ctx, common.HexToHash(h))
+		if err != nil {
+			log.Printf("[warn] trader backfill: %v", err)
+			continue
+		}
+		if err := ix.Repo.SetTrader(ctx, h, from.Hex()); err != nil {
+			return err
+		}
+		touched[from.Hex()] = true
+	}
+	// Attributed swaps may predate the trader's position, so replay it.
+	for trader := range touched {
+		if err := ix.Repo.RebuildPositions(ctx, trader); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+-- Materialized OHLCV rollups maintained by the indexer on every swap.
+-- open_*/close_* positions keep open/close correct when logs arrive out of order.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS candles_1m (
+  pool_address TEXT NOT NULL REFERENCES pools(pool_address) ON DELETE CASCADE,
+  bucket_time  TIMESTAMPTZ NOT NULL,
+  open         NUMERIC NOT NULL,
+  high         NUMERIC NOT NULL,
+  low          NUMERIC NOT NULL,
+  close        NUMERIC NOT NULL,
+  volume_usdc  NUMERIC NOT NULL DEFAULT 0,
+  volume_token NUMERIC NOT NULL DEFAULT 0,
+  trades       INT NOT NULL DEFAULT 0,
+  open_block   BIGINT NOT NULL,
+  open_log     INT NOT NULL,
+  close_block  BIGINT NOT NULL,
+  close_log    INT NOT NULL,
+  PRIMARY KEY (pool_address, bucket_time)
+);
+
+CREATE TABLE IF NOT EXISTS candles_5m (
+  pool_address TEXT NOT NULL REFERENCES pools(pool_address) ON DELETE CASCADE,
+  bucket_time  TIMESTAMPTZ NOT NULL,
+  open         NUMERIC NOT NULL,
+  high         NUMERIC NOT NULL,
+  low          NUMERIC NOT NULL,
+  close        NUMERIC NOT NULL,
+  volume_usdc  NUMERIC NOT NULL DEFAULT 0,
+  volume_token NUMERIC NOT NULL DEFAULT 0,
+  trades       INT NOT NULL DEFAULT 0,
+  open_block   BIGINT NOT NULL,
+  open_log     INT NOT NULL,
+  close_block  BIGINT NOT NULL,
+  close_log    INT NOT NULL,
+  PRIMARY KEY (pool_address, bucket_time)
+);
+
+CREATE TABLE IF NOT EXISTS candles_1h (
+  pool_address TEXT NOT NULL REFERENCES pools(pool_address) ON DELETE CASCADE,
+  bucket_time  TIMESTAMPTZ NOT NULL,
+  open         NUMERIC NOT NULL,
+  high         NUMERIC NOT NULL,
+  low          NUMERIC NOT NULL,
+  close        NUMERIC NOT NULL,
+  volume_usdc  NUMERIC NOT NULL DEFAULT 0,
+  volume_token NUMERIC NOT NULL DEFAULT 0,
+  trades       INT NOT NULL DEFAULT 0,
+  open_block   BIGINT NOT NULL,
+  open_log     INT NOT NULL,
+  close_block  BIGINT NOT NULL,
+  close_log    INT NOT NULL,
+  PRIMARY KEY (pool_address, bucket_time)
+);
+
+CREATE TABLE IF NOT EXISTS candles_1d (
+  pool_address TEXT NOT NULL REFERENCES pools(pool_address) ON DELETE CASCADE,
+  bucket_time  TIMESTAMPTZ NOT NULL,
+  open         NUMERIC NOT NULL,
+  high         NUMERIC NOT NULL,
+  low          NUMERIC NOT NULL,
+  close        NUMERIC NOT NULL,
+  volume_usdc  NUMERIC NOT NULL DEFAULT 0,
+  volume_token NUMERIC NOT NULL DEFAULT 0,
+  trades       INT NOT NULL DEFAULT 0,
+  open_block   BIGINT NOT NULL,
+  open_log     INT NOT NULL,
+  close_block  BIGINT NOT NULL,
+  close_log    INT NOT NULL,
+  PRIMARY KEY (pool_address, bucket_time)
+);
+
+COMMIT;
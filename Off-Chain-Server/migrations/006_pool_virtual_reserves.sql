@@ -0,0 +1,3 @@
+-- Immutable virtual reserves read from each LaunchPool at creation, used for off-chain quotes.
+ALTER TABLE pools ADD COLUMN IF NOT EXISTS virtual_usdc NUMERIC;
+ALTER TABLE pools ADD COLUMN IF NOT EXISTS virtual_token NUMERIC;
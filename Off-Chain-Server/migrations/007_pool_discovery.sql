@@ -0,0 +1,9 @@
+-- Pool discovery: on-chain creator plus indexes backing /pools search, filters and 24h stats.
+ALTER TABLE pools ADD COLUMN IF NOT EXISTS creator_address TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_pools_creator ON pools(LOWER(creator_address));
+CREATE INDEX IF NOT EXISTS idx_pools_created_time ON pools(created_time);
+CREATE INDEX IF NOT EXISTS idx_pool_metadata_name ON pool_metadata(LOWER(name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_pool_metadata_symbol ON pool_metadata(LOWER(symbol) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_swaps_pool_time ON swaps(pool_address, block_time);
+CREATE INDEX IF NOT EXISTS idx_price_updates_pool_time ON price_updates(pool_address, block_time);
@@ -0,0 +1,2 @@
+-- Token decimals read from the token's decimals() at discovery, used to format amounts and prices.
+ALTER TABLE pools ADD COLUMN IF NOT EXISTS token_decimals SMALLINT;
@@ -0,0 +1,16 @@
+-- Token total supply read at discovery (project tokens are fixed-supply), and a view deriving
+-- supply-based valuations. Amounts are in base units; market_cap, fdv and tvl are USDC wei.
+ALTER TABLE pools ADD COLUMN IF NOT EXISTS total_supply NUMERIC;
+
+CREATE OR REPLACE VIEW pool_valuations AS
+SELECT p.pool_address,
+	s.total_supply,
+	GREATEST(s.total_supply - COALESCE(p.reserve_token, 0), 0) AS circulating_supply,
+	TRUNC(GREATEST(s.total_supply - COALESCE(p.reserve_token, 0), 0) * p.spot_x18 / 1e18) AS market_cap,
+	TRUNC(s.total_supply * p.spot_x18 / 1e18) AS fdv,
+	TRUNC(COALESCE(p.reserve_usdc, 0) + COALESCE(p.reserve_token, 0) * COALESCE(p.spot_x18, 0) / 1e18) AS tvl
+FROM pools p
+LEFT JOIN paxscan_cache c ON c.token_address = LOWER(p.token_address)
+CROSS JOIN LATERAL (
+	SELECT COALESCE(p.total_supply, CASE WHEN c.total_supply ~ '^[0-9]+$' THEN c.total_supply::numeric END) AS total_supply
+) s;
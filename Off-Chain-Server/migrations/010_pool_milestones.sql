@@ -0,0 +1,18 @@
+-- Bonding-curve progress: the creating factory, current progress toward graduation, and the first
+-- crossing of each configured milestone.
+ALTER TABLE pools ADD COLUMN IF NOT EXISTS factory_address TEXT;
+ALTER TABLE pools ADD COLUMN IF NOT EXISTS progress_pct NUMERIC;
+
+CREATE TABLE IF NOT EXISTS pool_milestones (
+  pool_address TEXT NOT NULL REFERENCES pools(pool_address) ON DELETE CASCADE,
+  name         TEXT NOT NULL,
+  kind         TEXT NOT NULL,
+  threshold    NUMERIC NOT NULL,
+  graduation   BOOLEAN NOT NULL DEFAULT FALSE,
+  block_number BIGINT NOT NULL,
+  tx_hash      TEXT NOT NULL,
+  block_time   TIMESTAMPTZ,
+  PRIMARY KEY (pool_address, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pool_milestones_graduated ON pool_milestones(block_number DESC, pool_address) WHERE graduation;
@@ -0,0 +1,7 @@
+-- The transaction sender behind each swap and liquidity event. Pool events name the router as
+-- sender when trades go through LaunchpadRouter, so per-account views key on this instead.
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS trader TEXT;
+ALTER TABLE liquidity_events ADD COLUMN IF NOT EXISTS trader TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_swaps_trader ON swaps(trader, block_number DESC, log_index DESC);
+CREATE INDEX IF NOT EXISTS idx_liquidity_events_trader ON liquidity_events(trader);
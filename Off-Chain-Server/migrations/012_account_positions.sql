@@ -0,0 +1,22 @@
+-- Per-trader positions maintained incrementally from swaps with average-cost accounting.
+-- token_balance/cost_basis_usdc describe tokens bought through the pool and not yet sold;
+-- realized_pnl_usdc accumulates (sell proceeds - average cost of the tokens sold).
+CREATE TABLE IF NOT EXISTS account_positions (
+  trader             TEXT NOT NULL,
+  pool_address       TEXT NOT NULL REFERENCES pools(pool_address) ON DELETE CASCADE,
+  token_balance      NUMERIC NOT NULL DEFAULT 0,
+  cost_basis_usdc    NUMERIC NOT NULL DEFAULT 0,
+  realized_pnl_usdc  NUMERIC NOT NULL DEFAULT 0,
+  bought_token       NUMERIC NOT NULL DEFAULT 0,
+  sold_token         NUMERIC NOT NULL DEFAULT 0,
+  spent_usdc         NUMERIC NOT NULL DEFAULT 0,
+  received_usdc      NUMERIC NOT NULL DEFAULT 0,
+  trades             INT NOT NULL DEFAULT 0,
+  last_block         BIGINT NOT NULL,
+  last_log           INT NOT NULL,
+  updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (trader, pool_address)
+);
+
+-- Realized PnL of each sell at the time it happened (NULL for buys and unattributed swaps).
+ALTER TABLE swaps ADD COLUMN IF NOT EXISTS realized_pnl NUMERIC;
@@ -0,0 +1,57 @@
+-- Leaderboard rollups, rebuilt periodically by the indexer (and cmd/rollups)
+-- for each period: '24h', '7d' and 'all'.
+CREATE TABLE IF NOT EXISTS leaderboard_traders (
+  period             TEXT NOT NULL,
+  trader             TEXT NOT NULL,
+  volume_usdc        NUMERIC NOT NULL,
+  realized_pnl_usdc  NUMERIC NOT NULL,
+  trades             INT NOT NULL,
+  pools              INT NOT NULL,
+  refreshed_at       TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (period, trader)
+);
+CREATE INDEX IF NOT EXISTS idx_lb_traders_volume ON leaderboard_traders(period, volume_usdc DESC);
+CREATE INDEX IF NOT EXISTS idx_lb_traders_pnl ON leaderboard_traders(period, realized_pnl_usdc DESC);
+
+-- Creator share of a swap's 1% fee (75%), mirroring LaunchPool. Buys are
+-- exact; sells emit the post-fee amount, so the gross is recovered as
+-- amount_out * 100 / 99 and may differ from the on-chain fee by 1 wei.
+CREATE OR REPLACE FUNCTION creator_fee_usdc(usdc_to_token BOOLEAN, amount_in NUMERIC, amount_out NUMERIC)
+RETURNS NUMERIC LANGUAGE SQL IMMUTABLE AS $$
+  SELECT TRUNC(TRUNC(CASE WHEN usdc_to_token THEN amount_in ELSE TRUNC(amount_out * 100 / 99) END / 100) * 75 / 100)
+$$;
+
+-- fees_usdc is the creator fee earned on swaps in the period (creator_fee_usdc);
+-- fees_collected_usdc is what creators claimed in the period (creator_fees).
+CREATE TABLE IF NOT EXISTS leaderboard_creators (
+  period              TEXT NOT NULL,
+  creator             TEXT NOT NULL,
+  fees_usdc           NUMERIC NOT NULL,
+  fees_collected_usdc NUMERIC NOT NULL DEFAULT 0,
+  volume_usdc         NUMERIC NOT NULL,
+  trades              INT NOT NULL,
+  pools               INT NOT NULL,
+  refreshed_at        TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (period, creator)
+);
+CREATE INDEX IF NOT EXISTS idx_lb_creators_fees ON leaderboard_creators(period, fees_usdc DESC);
+CREATE INDEX IF NOT EXISTS idx_lb_creators_volume ON leaderboard_creators(period, volume_usdc DESC);
+
+-- new_holders counts traders whose first buy of the pool falls in the period;
+-- holders is the current number of traders with a tracked token balance.
+CREATE TABLE IF NOT EXISTS leaderboard_pools (
+  period         TEXT NOT NULL,
+  pool_address   TEXT NOT NULL REFERENCES pools(pool_address) ON DELETE CASCADE,
+  volume_usdc    NUMERIC NOT NULL,
+  trades         INT NOT NULL,
+  traders        INT NOT NULL,
+  new_holders    INT NOT NULL,
+  holders        INT NOT NULL,
+  refreshed_at   TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (period, pool_address)
+);
+CREATE INDEX IF NOT EXISTS idx_lb_pools_volume ON leaderboard_pools(period, volume_usdc DESC);
+CREATE INDEX IF NOT EXISTS idx_lb_pools_new_holders ON leaderboard_pools(period, new_holders DESC);
+
+CREATE INDEX IF NOT EXISTS idx_swaps_block_time ON swaps(block_time);
+CREATE INDEX IF NOT EXISTS idx_creator_fees_block_time ON creator_fees(block_time);
@@ -0,0 +1,3 @@
+-- The creator dashboard counts comments per pool case-insensitively; fees use
+-- creator_fee_usdc from 013_leaderboards.sql.
+CREATE INDEX IF NOT EXISTS idx_comments_pool_lower ON comments(LOWER(pool_address));
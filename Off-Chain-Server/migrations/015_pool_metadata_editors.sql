@@ -0,0 +1,20 @@
+-- Metadata edits are limited to the pool's on-chain creator and the editors it delegates to.
+CREATE TABLE IF NOT EXISTS pool_metadata_editors (
+  pool_address  TEXT NOT NULL REFERENCES pools(pool_address) ON DELETE CASCADE,
+  editor        TEXT NOT NULL,
+  granted_by    TEXT NOT NULL,
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (pool_address, editor)
+);
+CREATE INDEX IF NOT EXISTS idx_pool_metadata_editors_editor ON pool_metadata_editors(editor);
+
+-- created_by was overwritten with each caller; it now records the first
+-- editor and updated_by the latest one.
+ALTER TABLE pool_metadata ADD COLUMN IF NOT EXISTS updated_by TEXT;
+
+-- Writes are keyed by the indexer's checksummed pool address and token from
+-- now on; align rows saved under a client-supplied spelling.
+UPDATE pool_metadata m SET pool_address = p.pool_address, token_address = p.token_address
+FROM pools p
+WHERE LOWER(p.pool_address) = LOWER(m.pool_address) AND (m.pool_address <> p.pool_address OR m.token_address <> p.token_address)
+  AND NOT EXISTS (SELECT 1 FROM pool_metadata d WHERE d.pool_address = p.pool_address AND d.pool_address <> m.pool_address);
@@ -0,0 +1,33 @@
+-- Every change to pool_metadata and profiles, numbered per entity.
+-- snapshot is the full set of versioned fields after the change; diff maps
+-- each changed field to {"from", "to"}.
+CREATE TABLE IF NOT EXISTS metadata_revisions (
+  id             BIGSERIAL PRIMARY KEY,
+  entity         TEXT NOT NULL CHECK (entity IN ('pool', 'profile')),
+  entity_key     TEXT NOT NULL,
+  revision       INT NOT NULL,
+  editor         TEXT,
+  source         TEXT NOT NULL,
+  snapshot       JSONB NOT NULL,
+  diff           JSONB NOT NULL,
+  reverted_from  INT,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (entity, entity_key, revision)
+);
+
+-- Baseline revisions for rows written before history was kept.
+INSERT INTO metadata_revisions(entity, entity_key, revision, editor, source, snapshot, diff, created_at)
+SELECT 'pool', pool_address, 1, created_by, 'import',
+  jsonb_build_object('name', name, 'symbol', symbol, 'description', description, 'website_url', website_url,
+    'twitter_url', twitter_url, 'telegram_url', telegram_url, 'logo_url', logo_url, 'banner_url', banner_url),
+  '{}'::jsonb, updated_at
+FROM pool_metadata
+ON CONFLICT DO NOTHING;
+
+INSERT INTO metadata_revisions(entity, entity_key, revision, editor, source, snapshot, diff, created_at)
+SELECT 'profile', address, 1, address, 'import',
+  jsonb_build_object('username', username, 'bio', bio, 'avatar_url', avatar_url, 'banner_url', banner_url,
+    'website_url', website_url, 'twitter_url', twitter_url, 'telegram_url', telegram_url),
+  '{}'::jsonb, updated_at
+FROM profiles
+ON CONFLICT DO NOTHING;
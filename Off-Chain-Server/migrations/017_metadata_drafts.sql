@@ -0,0 +1,24 @@
+-- Metadata prepared before createPool, keyed by token and creator wallet.
+-- The indexer promotes a draft into pool_metadata once it sees PoolCreated
+-- for the token and the pool's creator() matches.
+CREATE TABLE IF NOT EXISTS metadata_drafts (
+  token_address  TEXT NOT NULL,
+  creator        TEXT NOT NULL,
+  name           TEXT NOT NULL CHECK (char_length(name) <= 50),
+  symbol         TEXT NOT NULL CHECK (symbol ~ '^[A-Z]{3,10}$'),
+  description    TEXT CHECK (char_length(description) <= 500),
+  website_url    TEXT,
+  twitter_url    TEXT,
+  telegram_url   TEXT,
+  logo_url       TEXT,
+  banner_url     TEXT,
+  -- pending | promoted | skipped (the pool already had metadata)
+  status         TEXT NOT NULL DEFAULT 'pending',
+  pool_address   TEXT,
+  promoted_at    TIMESTAMPTZ,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (token_address, creator)
+);
+CREATE INDEX IF NOT EXISTS idx_metadata_drafts_creator ON metadata_drafts(creator);
+CREATE INDEX IF NOT EXISTS idx_metadata_drafts_pending ON metadata_drafts(token_address) WHERE status = 'pending';
@@ -0,0 +1,17 @@
+-- Scoped, revocable API keys for bots, apps and scripts. Only the SHA-256
+-- of a key is stored; the key itself is shown once when it is created.
+CREATE TABLE IF NOT EXISTS api_keys (
+  id            BIGSERIAL PRIMARY KEY,
+  address       TEXT NOT NULL,
+  name          TEXT NOT NULL DEFAULT '' CHECK (char_length(name) <= 64),
+  key_prefix    TEXT NOT NULL,
+  key_hash      TEXT NOT NULL UNIQUE,
+  -- read | write:comments | write:metadata
+  scopes        TEXT[] NOT NULL,
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_used_at  TIMESTAMPTZ,
+  expires_at    TIMESTAMPTZ,
+  revoked_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_address ON api_keys(address, created_at DESC);
@@ -0,0 +1,9 @@
+-- Session ids and device metadata for listing and revoking sessions.
+-- The token stays the lookup key; id is what clients see.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS id BIGSERIAL;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS user_agent TEXT;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS ip TEXT;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_id ON sessions(id);
+CREATE INDEX IF NOT EXISTS idx_auth_nonces_created_at ON auth_nonces(created_at);
@@ -0,0 +1,33 @@
+-- Token buckets for the Postgres rate limit backend, shared by API instances.
+-- full_at is when the bucket will have refilled; later rows are pruned.
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+  bucket_key  TEXT PRIMARY KEY,
+  tokens      DOUBLE PRECISION NOT NULL,
+  updated_at  TIMESTAMPTZ NOT NULL,
+  full_at     TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_full_at ON rate_limit_buckets(full_at);
+
+-- Takes one token from a bucket refilling at p_rate tokens per second up to
+-- p_burst. The row lock makes concurrent takes on one key serialize.
+CREATE OR REPLACE FUNCTION rate_limit_take(p_key TEXT, p_rate DOUBLE PRECISION, p_burst DOUBLE PRECISION)
+RETURNS TABLE(allowed BOOLEAN, remaining DOUBLE PRECISION) LANGUAGE plpgsql AS $$
+DECLARE
+  avail DOUBLE PRECISION;
+BEGIN
+  INSERT INTO rate_limit_buckets(bucket_key, tokens, updated_at, full_at) VALUES(p_key, p_burst, NOW(), NOW())
+  ON CONFLICT(bucket_key) DO NOTHING;
+  SELECT LEAST(p_burst, b.tokens + GREATEST(0, EXTRACT(EPOCH FROM NOW() - b.updated_at)::DOUBLE PRECISION) * p_rate)
+    INTO avail FROM rate_limit_buckets b WHERE b.bucket_key = p_key FOR UPDATE;
+  allowed := avail >= 1;
+  IF allowed THEN
+    avail := avail - 1;
+  END IF;
+  remaining := avail;
+  UPDATE rate_limit_buckets
+     SET tokens = avail, updated_at = NOW(), full_at = NOW() + make_interval(secs => (p_burst - avail) / p_rate)
+   WHERE bucket_key = p_key;
+  RETURN NEXT;
+END
+$$;
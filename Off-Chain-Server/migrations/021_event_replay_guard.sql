@@ -0,0 +1,11 @@
+-- Backfill restarts and overlapping polls replay logs. Keep the first copy of
+-- each swap and price update and make (tx_hash, log_index) unique so the
+-- indexer can skip replays before touching the rollups.
+-- Run ./rollups afterwards to rebuild candles inflated by earlier replays.
+DELETE FROM swaps a USING swaps b
+WHERE a.tx_hash = b.tx_hash AND a.log_index = b.log_index AND a.id > b.id;
+DELETE FROM price_updates a USING price_updates b
+WHERE a.tx_hash = b.tx_hash AND a.log_index = b.log_index AND a.id > b.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_swaps_tx_log ON swaps(tx_hash, log_index);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_price_updates_tx_log ON price_updates(tx_hash, log_index);
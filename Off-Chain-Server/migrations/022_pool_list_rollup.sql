@@ -0,0 +1,4 @@
+-- Price at the start of each leaderboard period (or the first price for
+-- younger pools), so /pools can read 24h volume and change from the rollup
+-- instead of aggregating swaps and price_updates on every request.
+ALTER TABLE leaderboard_pools ADD COLUMN IF NOT EXISTS open_x18 NUMERIC;